@@ -0,0 +1,378 @@
+// Package bedfusion sorts, merges and pads bed files.
+//
+// It offers the same functionality as the bedfusion command line tool,
+// but reads from io.Readers and writes to io.Writers instead of files,
+// takes its options as plain Go values and writes warnings to a
+// writer of choice instead of standard error.
+//
+// A typical use is to create a Bedfile with New, read one or more bed
+// files into it with Read and then call Fuse, which pads, merges,
+// deduplicates and sorts the regions in the same way as the default
+// command of the command line tool, before writing the result with Write.
+// The steps can also be run one by one with Pad, MergeAndPad,
+// Deduplicate and Sort.
+//
+// # Versioning
+//
+// This package follows semantic versioning: exported identifiers are
+// not removed or changed in incompatible ways within a major version,
+// new options and methods might be added in minor versions. The zero
+// value of new options keeps the previous behaviour. The packages under
+// internal/ are not covered and can change at any time.
+package bedfusion
+
+import (
+	"cmp"
+	"io"
+	"slices"
+
+	"github.com/hbesfb/bedfusion/internal/bed"
+)
+
+// The option values below are the same as in the internal/bed
+// package, which is verified by TestOptionValues
+
+// Sorting types
+const (
+	LexST  = "lex"  // lexicographic sorting (chr: 1 < 10 < 2 < MT < X)
+	NatST  = "nat"  // natural sorting (chr: 1 < 2 < 10 < MT < X)
+	CcsST  = "ccs"  // custom chromosome sorting from Options.ChrOrder
+	FidxST = "fidx" // use ordering from the fasta index file
+)
+
+// Input formats
+const (
+	BedIF   = "bed"   // bed files
+	GFF3IF  = "gff3"  // GFF3 annotation files
+	GTFIF   = "gtf"   // GTF annotation files
+	VcfIF   = "vcf"   // VCF files
+	BedpeIF = "bedpe" // BEDPE files, see Pairs
+)
+
+// Padding types
+const (
+	SafePT  = "safe"  // fail if a chromosome is not in the fasta index file
+	LaxPT   = "lax"   // only pad regions on chromosomes in the fasta index file
+	ForcePT = "force" // pad all regions, the fasta index file is optional
+)
+
+// Header policies
+const (
+	FirstHP  = "first"  // keep the header lines of the first file
+	ConcatHP = "concat" // keep the header lines of all files, without duplicates
+	DropHP   = "drop"   // drop all header lines
+	TrackHP  = "track"  // replace the header lines with a generated track line
+)
+
+// Comment policies
+const (
+	KeepCP = "keep" // keep comments in front of the region following them
+	TopCP  = "top"  // move comments to the top, after the header lines
+	DropCP = "drop" // drop comments
+)
+
+// Built-in chromosome alias sets
+const (
+	GRCh37CA = "grch37" // GRCh37/hg19 primary assembly
+	GRCh38CA = "grch38" // GRCh38/hg38 primary assembly
+)
+
+// Named input used for the optional fasta index and chromosome
+// alias files. The name is only used in messages. Gzip and BGZF
+// compressed content is decompressed automatically.
+type Input struct {
+	Name   string
+	Reader io.Reader
+}
+
+// Options corresponding to the flags of the command line tool,
+// the zero value of each option gives the default behaviour
+type Options struct {
+	// Input
+	StrandCol   int    // column containing the strand (1-based), 0 = unset
+	FeatCol     int    // column containing the feature (1-based), 0 = unset
+	FastaIdx    *Input // chromosome lengths, e.g. a fasta index, fasta, .dict or BAM file
+	ChrAlias    *Input // chromosome alias file, see ChrNaming
+	ChrAliasSet string // built-in alias set (GRCh37CA or GRCh38CA) to use instead of ChrAlias
+	ChrNaming   string // naming style to convert the chromosome names to
+	InputFormat string // BedIF (default), GFF3IF, GTFIF, VcfIF or BedpeIF
+	Bed12       bool   // the input is BED12, blocks are united when merging and extended when padding
+
+	// Mixed inputs, the files are matched to the column maps by the name given to Read
+	Schema     int      // project every line to this number of columns, 0 = unset
+	SchemaFill string   // value of the columns added by the projection, default .
+	ColMaps    []string // column mapping of one file, as <file>=<col>,<col>,... (1-based, . = fill)
+
+	// Annotation files, see InputFormat
+	FeatureTypes []string // feature types to keep, if empty all are kept
+	AttrFilters  []string // only keep features with these attribute values, as <attribute>=<value>
+	Attrs        []string // attributes written as optional columns after the strand column
+	FeatAttr     string   // attribute used as the feature, see FeatCol
+
+	// VCF files, see InputFormat
+	InfoFields []string // INFO fields written as optional columns after the ID column
+
+	// Sorting
+	SortType    string   // LexST (default), NatST, CcsST or FidxST
+	ChrOrder    []string // chromosome order used by CcsST
+	Deduplicate bool     // remove duplicated lines when not merging
+	Threads     int      // number of chromosomes merged and sorted concurrently, 0 = 1
+
+	// Merging
+	NoMerge bool     // do not merge regions
+	Overlap int      // overlap between regions to be merged, -1 = do not merge touching regions
+	ColOps  []string // column operations used when merging, as <column>:<operation> (e.g. 5:sum)
+
+	// Padding
+	Padding       int    // padding in bp added to both ends of the regions
+	PaddingType   string // SafePT (default), LaxPT or ForcePT
+	FirstBase     int    // start coordinate of the first base on each chromosome, 0 or 1
+	PadUpstream   int    // padding in bp added upstream, requires StrandCol
+	PadDownstream int    // padding in bp added downstream, requires StrandCol
+	PadLeft       int    // padding in bp added before the start
+	PadRight      int    // padding in bp added after the stop
+
+	// Output
+	Bgzip         bool   // compress the output in the BGZF format
+	HeaderPolicy  string // FirstHP (default), ConcatHP, DropHP or TrackHP
+	TrackLine     string // track line used by TrackHP, if empty it is named bedfusion
+	CommentPolicy string // KeepCP (default), TopCP or DropCP, for # lines after the first region
+
+	// Warnings are written to this writer, if nil they are discarded
+	Warnings io.Writer
+}
+
+// Bed file region
+type Line struct {
+	Chr      string
+	Start    int
+	Stop     int
+	Strand   string   // only set if Options.StrandCol is set
+	Feat     string   // only set if Options.FeatCol is set
+	Fields   []string // all columns of the line
+	Comments []string // comment lines written in front of the line, see Options.CommentPolicy
+}
+
+// BEDPE pair of regions (anchors), the strands are
+// only set if the BEDPE file has strand columns
+type Pair struct {
+	A        Line     // first anchor, its fields are chr, start and stop
+	B        Line     // second anchor, its fields are chr, start and stop
+	Fields   []string // all columns of the line
+	Comments []string // comment lines written in front of the line, see Options.CommentPolicy
+}
+
+// Bed file holding the header and regions read
+type Bedfile struct {
+	bf bed.Bedfile
+}
+
+// Create new bed file from the options
+//
+// The options are verified, and the fasta index and chromosome
+// alias files are read if they are given
+func New(opts Options) (*Bedfile, error) {
+	b := &Bedfile{bf: bed.Bedfile{
+		StrandCol:     opts.StrandCol,
+		FeatCol:       opts.FeatCol,
+		ChrAliasSet:   opts.ChrAliasSet,
+		ChrNaming:     opts.ChrNaming,
+		InputFormat:   cmp.Or(opts.InputFormat, BedIF),
+		Bed12:         opts.Bed12,
+		Schema:        opts.Schema,
+		SchemaFill:    opts.SchemaFill,
+		ColMap:        slices.Clone(opts.ColMaps),
+		FeatureType:   slices.Clone(opts.FeatureTypes),
+		AttrFilter:    slices.Clone(opts.AttrFilters),
+		Attr:          slices.Clone(opts.Attrs),
+		FeatAttr:      opts.FeatAttr,
+		InfoField:     slices.Clone(opts.InfoFields),
+		SortType:      cmp.Or(opts.SortType, LexST),
+		ChrOrder:      slices.Clone(opts.ChrOrder),
+		Deduplicate:   opts.Deduplicate,
+		Threads:       cmp.Or(opts.Threads, 1),
+		NoMerge:       opts.NoMerge,
+		Overlap:       opts.Overlap,
+		ColOp:         slices.Clone(opts.ColOps),
+		Padding:       opts.Padding,
+		PaddingType:   cmp.Or(opts.PaddingType, SafePT),
+		FirstBase:     opts.FirstBase,
+		PadUpstream:   opts.PadUpstream,
+		PadDownstream: opts.PadDownstream,
+		PadLeft:       opts.PadLeft,
+		PadRight:      opts.PadRight,
+		Bgzip:         opts.Bgzip,
+		HeaderPolicy:  cmp.Or(opts.HeaderPolicy, FirstHP),
+		TrackLine:     opts.TrackLine,
+		CommentPolicy: cmp.Or(opts.CommentPolicy, KeepCP),
+	}}
+	warnings := opts.Warnings
+	if warnings == nil {
+		warnings = io.Discard
+	}
+	b.bf.SetWarningWriter(warnings)
+
+	// The names are set before verifying, as the
+	// combinations of options depend on them
+	if opts.FastaIdx != nil {
+		b.bf.FastaIdx = cmp.Or(opts.FastaIdx.Name, "fasta index")
+	}
+	if opts.ChrAlias != nil {
+		b.bf.ChrAlias = cmp.Or(opts.ChrAlias.Name, "chromosome alias")
+	}
+	if err := b.bf.VerifyAndHandle(); err != nil {
+		return nil, err
+	}
+
+	if opts.ChrAlias != nil {
+		if err := b.bf.ReadChrAliasFrom(b.bf.ChrAlias, opts.ChrAlias.Reader); err != nil {
+			return nil, err
+		}
+	}
+	if opts.FastaIdx != nil {
+		if err := b.bf.ReadFastaIdxFrom(b.bf.FastaIdx, opts.FastaIdx.Reader); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Read bed file and add its regions to the bed file
+//
+// Several bed files can be read into the same bed file, they are
+// joined as if they were one file and must have the same number of
+// columns. The name is only used in messages. Gzip and BGZF compressed
+// content is decompressed automatically.
+func (b *Bedfile) Read(name string, r io.Reader) error {
+	if b.isBedpe() {
+		return b.bf.ReadPairsFrom(name, r)
+	}
+	return b.bf.ReadBedFrom(name, r)
+}
+
+// Pad, merge or deduplicate, and sort the regions in the same way as
+// the default command of the command line tool, according to the options
+func (b *Bedfile) Fuse() error {
+	if !b.bf.NoMerge {
+		if err := b.MergeAndPad(); err != nil {
+			return err
+		}
+	} else {
+		if err := b.Pad(); err != nil {
+			return err
+		}
+		if b.bf.Deduplicate {
+			b.Deduplicate()
+		}
+	}
+	return b.Sort()
+}
+
+// Pad the regions according to the padding options
+//
+// VCF files without a fasta index must give the
+// chromosome lengths in their ##contig lines
+func (b *Bedfile) Pad() error {
+	if err := b.bf.VerifyVcfChrLengths(); err != nil {
+		return err
+	}
+	if !b.bf.HasPadding() {
+		return nil
+	}
+	if b.isBedpe() {
+		return b.bf.PadPairs()
+	}
+	return b.bf.PadLines()
+}
+
+// Pad and merge the regions according to the padding and merging
+// options. Note that the regions are padded before they are merged.
+// BEDPE pairs are merged if both anchors overlap
+func (b *Bedfile) MergeAndPad() error {
+	if err := b.bf.VerifyVcfChrLengths(); err != nil {
+		return err
+	}
+	if b.isBedpe() {
+		return b.bf.MergeAndPadPairs()
+	}
+	if len(b.bf.Lines) == 0 {
+		return nil
+	}
+	return b.bf.MergeAndPadLines()
+}
+
+// Remove duplicated regions, BEDPE pairs are removed
+// if both anchors overlap an earlier pair
+func (b *Bedfile) Deduplicate() {
+	if b.isBedpe() {
+		b.bf.DeduplicatePairs()
+		return
+	}
+	b.bf.DeduplicateLines()
+}
+
+// Sort the regions according to the sorting options,
+// BEDPE pairs are sorted by the first and then the second anchor
+//
+// VCF files without a fasta index must give the chromosome
+// order in their ##contig lines when sorting by FidxST
+func (b *Bedfile) Sort() error {
+	if err := b.bf.VerifyVcfChrLengths(); err != nil {
+		return err
+	}
+	if b.isBedpe() {
+		return b.bf.SortPairs()
+	}
+	return b.bf.Sort()
+}
+
+// Write the header and regions to the writer
+func (b *Bedfile) Write(w io.Writer) error {
+	if b.isBedpe() {
+		return b.bf.WritePairsTo(w)
+	}
+	return b.bf.WriteBedTo(w)
+}
+
+// Header lines (browser, track or #) kept according to Options.HeaderPolicy
+func (b *Bedfile) Header() []string {
+	return slices.Clone(b.bf.Header)
+}
+
+// Regions in their current order, empty for BEDPE files
+func (b *Bedfile) Lines() []Line {
+	lines := make([]Line, len(b.bf.Lines))
+	for i, l := range b.bf.Lines {
+		lines[i] = toLine(l)
+	}
+	return lines
+}
+
+// BEDPE pairs in their current order, empty for other input formats
+func (b *Bedfile) Pairs() []Pair {
+	pairs := make([]Pair, len(b.bf.Pairs))
+	for i, p := range b.bf.Pairs {
+		pairs[i] = Pair{
+			A:        toLine(p.Anchors[0]),
+			B:        toLine(p.Anchors[1]),
+			Fields:   slices.Clone(p.Full),
+			Comments: slices.Clone(p.Comments),
+		}
+	}
+	return pairs
+}
+
+// Convert internal line to public line
+func toLine(l bed.Line) Line {
+	return Line{
+		Chr: l.Chr, Start: l.Start, Stop: l.Stop,
+		Strand: l.Strand, Feat: l.Feat,
+		Fields:   l.Full(),
+		Comments: slices.Clone(l.Comments),
+	}
+}
+
+// Returns true if the input is BEDPE
+func (b *Bedfile) isBedpe() bool {
+	return b.bf.InputFormat == BedpeIF
+}
@@ -0,0 +1,345 @@
+package bedfusion
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	"github.com/hbesfb/bedfusion/internal/bed"
+)
+
+func gzipString(t *testing.T, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestOptionValues(t *testing.T) {
+	t.Parallel()
+	received := []string{
+		LexST, NatST, CcsST, FidxST,
+		BedIF, GFF3IF, GTFIF, VcfIF, BedpeIF,
+		SafePT, LaxPT, ForcePT,
+		FirstHP, ConcatHP, DropHP, TrackHP,
+		KeepCP, TopCP, DropCP,
+		GRCh37CA, GRCh38CA,
+	}
+	expected := []string{
+		bed.LexST, bed.NatST, bed.CcsST, bed.FidxST,
+		bed.BedIF, bed.GFF3IF, bed.GTFIF, bed.VcfIF, bed.BedpeIF,
+		bed.SafePT, bed.LaxPT, bed.ForcePT,
+		bed.FirstHP, bed.ConcatHP, bed.DropHP, bed.TrackHP,
+		bed.KeepCP, bed.TopCP, bed.DropCP,
+		bed.GRCh37CA, bed.GRCh38CA,
+	}
+	if diff := deep.Equal(expected, received); diff != nil {
+		t.Error("expected VS received option values", diff)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		opts       Options
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "default options",
+			opts:    Options{},
+		},
+		{
+			testing: "padding with fasta index",
+			opts: Options{
+				Padding:  10,
+				FastaIdx: &Input{Name: "test.fasta.fai", Reader: strings.NewReader("1\t100\n")},
+			},
+		},
+		{
+			testing:    "padding without fasta index",
+			opts:       Options{Padding: 10},
+			shouldFail: true,
+		},
+		{
+			testing:    "fasta index sorting without fasta index",
+			opts:       Options{SortType: FidxST},
+			shouldFail: true,
+		},
+		{
+			testing:    "unknown column operation",
+			opts:       Options{ColOps: []string{"4:unknown"}},
+			shouldFail: true,
+		},
+		{
+			testing: "invalid fasta index",
+			opts: Options{
+				FastaIdx: &Input{Name: "test.fasta.fai", Reader: strings.NewReader("1\tx\n")},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "chromosome alias file",
+			opts: Options{
+				ChrAlias:  &Input{Name: "alias.txt", Reader: strings.NewReader("# ucsc\tensembl\nchr1\t1\n")},
+				ChrNaming: "ensembl",
+			},
+		},
+		{
+			testing: "unknown chromosome naming",
+			opts: Options{
+				ChrAlias:  &Input{Name: "alias.txt", Reader: strings.NewReader("# ucsc\tensembl\nchr1\t1\n")},
+				ChrNaming: "refseq",
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tc.opts)
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestFuse(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing          string
+		opts             Options
+		bedFiles         []string
+		expectedOutput   string
+		expectedWarnings string
+		shouldFail       bool
+	}
+	testCases := []testCase{
+		{
+			testing:        "merge and sort",
+			bedFiles:       []string{"browser position 1:1-100\n1\t1\t4\n1\t5\t9\n10\t5\t8\n1\t20\t30\n"},
+			expectedOutput: "browser position 1:1-100\n1\t1\t9\n1\t20\t30\n10\t5\t8\n",
+		},
+		{
+			testing:        "several bed files are joined",
+			bedFiles:       []string{"2\t1\t4\n", "1\t1\t4\n"},
+			expectedOutput: "1\t1\t4\n2\t1\t4\n",
+		},
+		{
+			testing:        "gzip compressed bed file",
+			bedFiles:       []string{"gzip:1\t5\t9\n1\t1\t4\n"},
+			expectedOutput: "1\t1\t9\n",
+		},
+		{
+			testing:        "no merge with deduplication",
+			opts:           Options{NoMerge: true, Deduplicate: true},
+			bedFiles:       []string{"1\t5\t9\n1\t1\t4\n1\t5\t9\n"},
+			expectedOutput: "1\t1\t4\n1\t5\t9\n",
+		},
+		{
+			testing: "lax padding writes warnings to the warning writer",
+			opts: Options{
+				Padding:     10,
+				PaddingType: LaxPT,
+				FastaIdx:    &Input{Name: "test.fasta.fai", Reader: strings.NewReader("1\t100\n")},
+			},
+			bedFiles:         []string{"1\t5\t9\n2\t5\t9\n"},
+			expectedOutput:   "1\t0\t19\n2\t5\t9\n",
+			expectedWarnings: "warning: chromosomes [2] not in fasta index file test.fasta.fai, no padding was added to regions on these chromosomes\n",
+		},
+		{
+			testing:          "empty bed file",
+			bedFiles:         []string{""},
+			expectedOutput:   "",
+			expectedWarnings: "",
+		},
+		{
+			testing: "gtf annotation file",
+			opts: Options{
+				InputFormat:  GTFIF,
+				FeatureTypes: []string{"exon"},
+				FeatAttr:     "gene_name",
+			},
+			bedFiles: []string{
+				"1\tensembl\texon\t1000\t1100\t.\t+\t.\tgene_name \"A\";\n" +
+					"1\tensembl\texon\t1050\t1200\t.\t+\t.\tgene_name \"B\";\n" +
+					"1\tensembl\tgene\t1000\t1200\t.\t+\t.\tgene_name \"A\";\n",
+			},
+			expectedOutput: "1\t999\t1100\t+\tA\n1\t1049\t1200\t+\tB\n",
+		},
+		{
+			testing: "vcf file with contig lengths",
+			opts: Options{
+				InputFormat: VcfIF,
+				InfoFields:  []string{"GENE"},
+				NoMerge:     true,
+				Padding:     10,
+			},
+			bedFiles: []string{
+				"##contig=<ID=1,length=105>\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+					"1\t100\trs1\tC\tT\t.\tPASS\tGENE=A\n",
+			},
+			expectedOutput: "1\t89\t105\trs1\tA\n",
+		},
+		{
+			testing: "vcf file without contig lines sorted by the fasta index",
+			opts: Options{
+				InputFormat: VcfIF,
+				SortType:    FidxST,
+			},
+			bedFiles: []string{
+				"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+					"1\t100\trs1\tC\tT\t.\tPASS\t.\n",
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "vcf file without contig lines padded",
+			opts: Options{
+				InputFormat: VcfIF,
+				NoMerge:     true,
+				Padding:     10,
+			},
+			bedFiles: []string{
+				"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\n" +
+					"1\t100\trs1\tC\tT\t.\tPASS\t.\n",
+			},
+			shouldFail: true,
+		},
+		{
+			testing:    "different number of columns",
+			bedFiles:   []string{"1\t1\t4\n", "1\t5\t9\tA\n"},
+			shouldFail: true,
+		},
+		{
+			testing:        "merged and sorted on several threads",
+			opts:           Options{SortType: NatST, Threads: 4},
+			bedFiles:       []string{"10\t1\t4\n2\t5\t9\n1\t3\t6\n", "2\t1\t4\n1\t1\t2\n"},
+			expectedOutput: "1\t1\t6\n2\t1\t9\n10\t1\t4\n",
+		},
+		{
+			testing:        "different number of columns projected to a schema",
+			opts:           Options{Schema: 4, NoMerge: true},
+			bedFiles:       []string{"1\t1\t4\n", "1\t5\t9\tA\t0\n"},
+			expectedOutput: "1\t1\t4\t.\n1\t5\t9\tA\n",
+		},
+		{
+			testing: "safe padding with chromosome not in the fasta index",
+			opts: Options{
+				Padding:  10,
+				FastaIdx: &Input{Name: "test.fasta.fai", Reader: strings.NewReader("1\t100\n")},
+			},
+			bedFiles:   []string{"2\t5\t9\n"},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var warnings, output bytes.Buffer
+			tc.opts.Warnings = &warnings
+			bf, err := New(tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, bedFile := range tc.bedFiles {
+				if content, ok := strings.CutPrefix(bedFile, "gzip:"); ok {
+					bedFile = gzipString(t, content)
+				}
+				if err = bf.Read("test.bed", strings.NewReader(bedFile)); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = bf.Fuse()
+			}
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if err := bf.Write(&output); err != nil {
+					t.Fatal(err)
+				}
+				if diff := deep.Equal(tc.expectedOutput, output.String()); diff != nil {
+					t.Error("expected VS received output", diff)
+				}
+				if diff := deep.Equal(tc.expectedWarnings, warnings.String()); diff != nil {
+					t.Error("expected VS received warnings", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Options{StrandCol: 4, FeatCol: 5, NoMerge: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bf.Read("test.bed", strings.NewReader("track name=test\n1\t1\t4\t+\tA\n")); err != nil {
+		t.Fatal(err)
+	}
+	expectedLines := []Line{
+		{
+			Chr: "1", Start: 1, Stop: 4, Strand: "+", Feat: "A",
+			Fields: []string{"1", "1", "4", "+", "A"},
+		},
+	}
+	if diff := deep.Equal(expectedLines, bf.Lines()); diff != nil {
+		t.Error("expected VS received lines", diff)
+	}
+	if diff := deep.Equal([]string{"track name=test"}, bf.Header()); diff != nil {
+		t.Error("expected VS received header", diff)
+	}
+	// The returned lines are copies
+	bf.Lines()[0].Fields[0] = "2"
+	if diff := deep.Equal(expectedLines, bf.Lines()); diff != nil {
+		t.Error("expected VS received lines after changing the returned lines", diff)
+	}
+}
+
+func TestPairs(t *testing.T) {
+	t.Parallel()
+	bf, err := New(Options{InputFormat: BedpeIF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "1\t10\t20\t2\t30\t40\tP1\t0\t+\t-\n1\t15\t25\t2\t35\t45\tP2\t0\t+\t-\n"
+	if err := bf.Read("test.bedpe", strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bf.Fuse(); err != nil {
+		t.Fatal(err)
+	}
+	expectedPairs := []Pair{
+		{
+			A:      Line{Chr: "1", Start: 10, Stop: 25, Strand: "+", Fields: []string{"1", "10", "25"}},
+			B:      Line{Chr: "2", Start: 30, Stop: 45, Strand: "-", Fields: []string{"2", "30", "45"}},
+			Fields: []string{"1", "10", "25", "2", "30", "45", "P1,P2", "0", "+", "-"},
+		},
+	}
+	if diff := deep.Equal(expectedPairs, bf.Pairs()); diff != nil {
+		t.Error("expected VS received pairs", diff)
+	}
+	var out strings.Builder
+	if err := bf.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal("1\t10\t25\t2\t30\t45\tP1,P2\t0\t+\t-\n", out.String()); diff != nil {
+		t.Error("expected VS received output", diff)
+	}
+}
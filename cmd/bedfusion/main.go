@@ -1,33 +1,68 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/alecthomas/kong"
 	kongyaml "github.com/alecthomas/kong-yaml"
 
 	"github.com/hbesfb/bedfusion/internal/bed"
+	"github.com/hbesfb/bedfusion/internal/server"
 )
 
 type session struct {
-	ConfigFile kong.ConfigFlag `env:"CONFIG_FILE" short:"c" help:"The path to configuration file (must be in key-value yaml format)"`
-	Bedfile    bed.Bedfile     `embed:""`
-	ctx        *kong.Context
+	ConfigFile  kong.ConfigFlag `env:"CONFIG_FILE" short:"c" help:"The path to configuration file (must be in key-value yaml format)"`
+	Fuse        fuseCmd         `cmd:"" default:"withargs" help:"Sort, merge and pad bed files. This is the default command, and is used when no command is given"`
+	Intersect   intersectCmd    `cmd:"" help:"Report overlaps between the input bed files and the bed files given by -b. Order of actions: 1. reading files 2. padding(*) 3. intersecting 4. deduplication(*) 5. sorting 6. writing output"`
+	Subtract    subtractCmd     `cmd:"" help:"Remove the regions in the bed files given by -b from the input bed files. Order of actions: 1. reading files 2. padding(*) 3. subtracting 4. deduplication(*) 5. sorting 6. writing output"`
+	Complement  complementCmd   `cmd:"" help:"Report the regions of the chromosomes in the fasta index file that are not covered by the input bed files. Must be used together with --fasta-idx or --fasta, and the output is always sorted in the order of the fasta index file, so --sort-type can only be ${fidxST}. Order of actions: 1. reading files 2. padding(*) 3. finding the complement 4. writing output"`
+	MakeWindows windowsCmd      `cmd:"" name:"makewindows" help:"Split the input regions, or the chromosomes in the fasta index file if no inputs are given, into windows of a fixed size. Order of actions: 1. reading files 2. padding(*) 3. making windows 4. deduplication(*) 5. sorting 6. writing output"`
+	Query       queryCmd        `cmd:"" help:"Report the lines of the input bed files that overlap the regions given by --region or --regions-file. Each line is reported once, in the order given by the sorting options. Order of actions: 1. reading files 2. padding(*) 3. querying 4. deduplication(*) 5. sorting 6. writing output"`
+	Serve       serveCmd        `cmd:"" help:"Start an HTTP server that sorts, merges and pads uploaded bed files in the same way as the default command. POST /fuse takes the bed files and the options (as JSON), and GET /health reports that the server is running, see docs/serve.md"`
+	Lint        lintCmd         `cmd:"" aliases:"validate" help:"Check the input bed files and report every problem found, with file, line, column and severity. The exit code reflects the worst severity: 0 = no problems, 3 = warnings, 4 = errors"`
+	ctx         *kong.Context
 }
 
-// Validate bed input
-func (s *session) Validate() error {
-	if err := s.Bedfile.VerifyAndHandle(); err != nil {
-		return err
-	}
-	return nil
+// Command that can be run
+type command interface {
+	run() (error, string)
+}
+
+// Returned by commands that ran successfully, but
+// report their result with an exit code (see lint)
+type exitCodeError int
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", int(e))
 }
 
 func main() {
 	var s session
 	// Getting variables
-	s.ctx = kong.Parse(&s,
-		kong.Description("Another tool for sorting and merging bed files.\n\n"+
-			"BedFusion follows the bed file standard outlined in: https://github.com/samtools/hts-specs/blob/94500cf76f049e898dec7af23097d877fde5894e/BEDv1.pdf \n\n"+
-			"Read priority order: 1. flags 2. configuration file 3. environmental variables \n\n"+
+	s.ctx = kong.Parse(&s, kongOptions()...)
+	cmd := s.ctx.Selected().Target.Addr().Interface().(command)
+	err, msg := cmd.run()
+	var exitCode exitCodeError
+	if errors.As(err, &exitCode) {
+		os.Exit(int(exitCode))
+	}
+	s.ctx.FatalIfErrorf(err, msg)
+}
+
+// Options of the command line parser
+func kongOptions() []kong.Option {
+	return []kong.Option{
+		kong.Description("Another tool for sorting and merging bed files.\n\n" +
+			"BedFusion follows the bed file standard outlined in: https://github.com/samtools/hts-specs/blob/94500cf76f049e898dec7af23097d877fde5894e/BEDv1.pdf \n\n" +
+			"Read priority order: 1. flags 2. configuration file 3. environmental variables \n\n" +
 			"Order of actions: 1. reading files 2. padding(*) 3. merging(*)/deduplication(*) 4. sorting 5. writing output (* = can be turned on/off using flags)"),
 		kong.Vars{
 			// Sorting types
@@ -35,45 +70,482 @@ func main() {
 			"natST":  bed.NatST,
 			"ccsST":  bed.CcsST,
 			"fidxST": bed.FidxST,
+			// Input formats
+			"bedIF":   bed.BedIF,
+			"gff3IF":  bed.GFF3IF,
+			"gtfIF":   bed.GTFIF,
+			"vcfIF":   bed.VcfIF,
+			"bedpeIF": bed.BedpeIF,
 			// Padding types
 			"failPT":  bed.SafePT,
 			"warnPT":  bed.LaxPT,
 			"forcePT": bed.ForcePT,
+			// Intersect modes
+			"overlapIM":   bed.OverlapIM,
+			"originalIM":  bed.OriginalIM,
+			"noOverlapIM": bed.NoOverlapIM,
+			"bothIM":      bed.BothIM,
+			// Query strands
+			"plusQS":  bed.PlusQS,
+			"minusQS": bed.MinusQS,
+			// Chromosome alias sets
+			"grch37CA": bed.GRCh37CA,
+			"grch38CA": bed.GRCh38CA,
+			// Lint formats
+			"textLF": bed.TextLF,
+			"jsonLF": bed.JSONLF,
+			// Header and comment policies
+			"firstHP":  bed.FirstHP,
+			"concatHP": bed.ConcatHP,
+			"dropHP":   bed.DropHP,
+			"trackHP":  bed.TrackHP,
+			"keepCP":   bed.KeepCP,
+			"topCP":    bed.TopCP,
+			"dropCP":   bed.DropCP,
+			// Last window policies
+			"keepLW":   bed.KeepLW,
+			"dropLW":   bed.DropLW,
+			"extendLW": bed.ExtendLW,
 		},
-		kong.Configuration(kongyaml.Loader),
+		kong.Configuration(configLoader),
 		kong.UsageOnError(),
-	)
-	s.ctx.FatalIfErrorf(s.run())
+	}
+}
+
+// Load yaml configuration file
+//
+// The options can either be given at the top level of the file, where
+// they apply to all commands, or in a section named after the command
+// (e.g. intersect:) where they only apply to that command
+func configLoader(r io.Reader) (kong.Resolver, error) {
+	resolver, err := kongyaml.Loader(r)
+	if err != nil {
+		return nil, err
+	}
+	return kong.ResolverFunc(func(ctx *kong.Context, parent *kong.Path, flag *kong.Flag) (any, error) {
+		value, err := resolver.Resolve(ctx, parent, flag)
+		if value != nil || err != nil {
+			return value, err
+		}
+		// Fall back to the option at the top level
+		return resolver.Resolve(ctx, &kong.Path{App: ctx.Model}, flag)
+	}), nil
+}
+
+// Sort, merge and pad bed files
+type fuseCmd struct {
+	Bedfile bed.Bedfile `embed:""`
+}
+
+// Validate bed input
+func (c *fuseCmd) Validate() error {
+	if err := c.Bedfile.VerifyInputs(); err != nil {
+		return err
+	}
+	if err := c.Bedfile.VerifyAndHandle(); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (s *session) run() (error, string) {
+func (c *fuseCmd) run() (error, string) {
+	// Pairs of regions are handled separately
+	if c.Bedfile.InputFormat == bed.BedpeIF {
+		return c.runPairs()
+	}
+	// Stream presorted bed files
+	if c.Bedfile.Presorted {
+		if err := c.Bedfile.Stream(); err != nil {
+			return err, "while streaming"
+		}
+		return nil, ""
+	}
+	// Sort bed files that do not fit into memory
+	if c.Bedfile.MaxMemory != "" {
+		if err := c.Bedfile.ExternalSort(); err != nil {
+			return err, "while sorting"
+		}
+		return nil, ""
+	}
 	// Read bed file
-	if err := s.Bedfile.Read(); err != nil {
+	if err := c.Bedfile.Read(); err != nil {
 		return err, "while reading"
 	}
-	if !s.Bedfile.NoMerge {
+	if !c.Bedfile.NoMerge {
 		// Merge and pad lines
-		if err := s.Bedfile.MergeAndPadLines(); err != nil {
-			return err, "while padding"
+		if err := c.Bedfile.MergeAndPadLines(); err != nil {
+			return err, "while merging and padding"
 		}
 	} else {
 		// Pad lines
-		if s.Bedfile.Padding != 0 {
-			if err := s.Bedfile.PadLines(); err != nil {
+		if c.Bedfile.HasPadding() {
+			if err := c.Bedfile.PadLines(); err != nil {
+				return err, "while padding"
+			}
+		}
+		// Deduplicate
+		if c.Bedfile.Deduplicate {
+			c.Bedfile.DeduplicateLines()
+		}
+	}
+	return sortAndWrite(&c.Bedfile)
+}
+
+// Sort, merge and pad BEDPE files
+func (c *fuseCmd) runPairs() (error, string) {
+	// Read BEDPE file
+	if err := c.Bedfile.ReadPairs(); err != nil {
+		return err, "while reading"
+	}
+	if !c.Bedfile.NoMerge {
+		// Merge and pad pairs
+		if err := c.Bedfile.MergeAndPadPairs(); err != nil {
+			return err, "while merging and padding"
+		}
+	} else {
+		// Pad pairs
+		if c.Bedfile.HasPadding() {
+			if err := c.Bedfile.PadPairs(); err != nil {
 				return err, "while padding"
 			}
 		}
 		// Deduplicate
-		if s.Bedfile.Deduplicate {
-			s.Bedfile.DeduplicateLines()
+		if c.Bedfile.Deduplicate {
+			c.Bedfile.DeduplicatePairs()
+		}
+	}
+	// Sort
+	if err := c.Bedfile.SortPairs(); err != nil {
+		return err, "while sorting"
+	}
+	// Write output
+	if err := c.Bedfile.WritePairs(); err != nil {
+		return err, "while writing"
+	}
+	return nil, ""
+}
+
+// Report overlaps between two sets of bed files
+type intersectCmd struct {
+	Bedfile   bed.Bedfile   `embed:""`
+	Intersect bed.Intersect `embed:""`
+}
+
+// Validate bed and intersect input
+func (c *intersectCmd) Validate() error {
+	if err := c.Bedfile.VerifyInputs(); err != nil {
+		return err
+	}
+	if err := c.Bedfile.VerifyAndHandle(); err != nil {
+		return err
+	}
+	if err := c.Intersect.VerifyAndHandle(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *intersectCmd) run() (error, string) {
+	// Read bed file
+	if err := c.Bedfile.Read(); err != nil {
+		return err, "while reading"
+	}
+	// Pad lines
+	if c.Bedfile.HasPadding() {
+		if err := c.Bedfile.PadLines(); err != nil {
+			return err, "while padding"
+		}
+	}
+	// Intersect
+	if err := c.Bedfile.Intersect(c.Intersect); err != nil {
+		return err, "while intersecting"
+	}
+	// Deduplicate
+	if c.Bedfile.Deduplicate {
+		c.Bedfile.DeduplicateLines()
+	}
+	return sortAndWrite(&c.Bedfile)
+}
+
+// Look up the lines overlapping regions
+type queryCmd struct {
+	Bedfile bed.Bedfile `embed:""`
+	Query   bed.Query   `embed:""`
+}
+
+// Validate bed and query input
+func (c *queryCmd) Validate() error {
+	if err := c.Bedfile.VerifyInputs(); err != nil {
+		return err
+	}
+	if c.Query.Strand != "" && c.Bedfile.StrandCol == 0 {
+		return fmt.Errorf("--strand must be used together with --strand-col")
+	}
+	if err := c.Bedfile.VerifyAndHandle(); err != nil {
+		return err
+	}
+	if err := c.Query.VerifyAndHandle(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *queryCmd) run() (error, string) {
+	// Read bed file
+	if err := c.Bedfile.Read(); err != nil {
+		return err, "while reading"
+	}
+	// Pad lines
+	if c.Bedfile.HasPadding() {
+		if err := c.Bedfile.PadLines(); err != nil {
+			return err, "while padding"
 		}
 	}
+	// Query
+	if err := c.Bedfile.Query(c.Query); err != nil {
+		return err, "while querying"
+	}
+	// Deduplicate
+	if c.Bedfile.Deduplicate {
+		c.Bedfile.DeduplicateLines()
+	}
+	return sortAndWrite(&c.Bedfile)
+}
+
+// Remove one set of regions from another
+type subtractCmd struct {
+	Bedfile  bed.Bedfile  `embed:""`
+	Subtract bed.Subtract `embed:""`
+}
+
+// Validate bed and subtract input
+func (c *subtractCmd) Validate() error {
+	if err := c.Bedfile.VerifyInputs(); err != nil {
+		return err
+	}
+	if err := c.Bedfile.VerifyAndHandle(); err != nil {
+		return err
+	}
+	if err := c.Subtract.VerifyAndHandle(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *subtractCmd) run() (error, string) {
+	// Read bed file
+	if err := c.Bedfile.Read(); err != nil {
+		return err, "while reading"
+	}
+	// Pad lines
+	if c.Bedfile.HasPadding() {
+		if err := c.Bedfile.PadLines(); err != nil {
+			return err, "while padding"
+		}
+	}
+	// Subtract
+	if err := c.Bedfile.Subtract(c.Subtract); err != nil {
+		return err, "while subtracting"
+	}
+	// Deduplicate
+	if c.Bedfile.Deduplicate {
+		c.Bedfile.DeduplicateLines()
+	}
+	return sortAndWrite(&c.Bedfile)
+}
+
+// Returns true if the flag is given on the command line
+func flagGiven(kctx *kong.Context, name string) bool {
+	for _, p := range kctx.Path {
+		if p.Flag != nil && p.Flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Report the regions not covered by the bed files
+type complementCmd struct {
+	Bedfile bed.Bedfile `embed:""`
+}
+
+// Validate bed input
+func (c *complementCmd) Validate(kctx *kong.Context) error {
+	if err := c.Bedfile.VerifyInputs(); err != nil {
+		return err
+	}
+	if c.Bedfile.FastaIdx == "" && c.Bedfile.Fasta == "" {
+		return fmt.Errorf("complement must be used together with --fasta-idx or --fasta")
+	}
+	// The output always follows the order of the fasta index file, so
+	// other sort types are not accepted. The default sort type can
+	// only be told apart from an explicit lex when given as a flag
+	if c.Bedfile.SortType != bed.FidxST &&
+		(c.Bedfile.SortType != bed.LexST || flagGiven(kctx, "sort-type")) {
+		return fmt.Errorf("the output is always sorted in the order of the fasta index file, --sort-type=%s can not be used", c.Bedfile.SortType)
+	}
+	c.Bedfile.SortType = bed.FidxST
+	if err := c.Bedfile.VerifyAndHandle(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *complementCmd) run() (error, string) {
+	// Read bed file
+	if err := c.Bedfile.Read(); err != nil {
+		return err, "while reading"
+	}
+	// Pad lines
+	if c.Bedfile.HasPadding() {
+		if err := c.Bedfile.PadLines(); err != nil {
+			return err, "while padding"
+		}
+	}
+	// Complement
+	if err := c.Bedfile.Complement(); err != nil {
+		return err, "while finding the complement"
+	}
+	return sortAndWrite(&c.Bedfile)
+}
+
+// Split regions or chromosomes into windows
+type windowsCmd struct {
+	Bedfile bed.Bedfile `embed:""`
+	Windows bed.Windows `embed:""`
+}
+
+// Validate bed and windows input
+func (c *windowsCmd) Validate() error {
+	if len(c.Bedfile.Inputs) == 0 && c.Bedfile.FastaIdx == "" && c.Bedfile.Fasta == "" {
+		return fmt.Errorf("makewindows must be given either input bed files, --fasta-idx or --fasta")
+	}
+	if err := c.Bedfile.VerifyAndHandle(); err != nil {
+		return err
+	}
+	if err := c.Windows.VerifyAndHandle(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *windowsCmd) run() (error, string) {
+	// Read bed file
+	if err := c.Bedfile.Read(); err != nil {
+		return err, "while reading"
+	}
+	// Pad lines
+	if c.Bedfile.HasPadding() {
+		if err := c.Bedfile.PadLines(); err != nil {
+			return err, "while padding"
+		}
+	}
+	// Make windows
+	if err := c.Bedfile.MakeWindows(c.Windows); err != nil {
+		return err, "while making windows"
+	}
+	// Deduplicate
+	if c.Bedfile.Deduplicate {
+		c.Bedfile.DeduplicateLines()
+	}
+	return sortAndWrite(&c.Bedfile)
+}
+
+// Report all problems in the bed files
+type lintCmd struct {
+	Bedfile bed.Bedfile `embed:""`
+	Lint    bed.Lint    `embed:""`
+}
+
+// Validate bed input
+func (c *lintCmd) Validate() error {
+	if err := c.Bedfile.VerifyInputs(); err != nil {
+		return err
+	}
+	if err := c.Bedfile.VerifyAndHandle(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *lintCmd) run() (error, string) {
+	// Lint
+	report, err := c.Bedfile.Lint()
+	if err != nil {
+		return err, "while linting"
+	}
+	// Write report
+	if err := c.Bedfile.WriteLintReport(report, c.Lint.LintFormat); err != nil {
+		return err, "while writing"
+	}
+	if exitCode := report.ExitCode(); exitCode != 0 {
+		return exitCodeError(exitCode), ""
+	}
+	return nil, ""
+}
+
+// Serve the sorting, merging and padding over HTTP
+type serveCmd struct {
+	Addr        string        `env:"ADDR" default:"localhost:8080" help:"Address the server listens on"`
+	MaxBodySize string        `env:"MAX_BODY_SIZE" default:"100M" help:"Largest accepted request body (e.g. 500M or 4G). The uploaded files are kept in memory while a request is handled"`
+	Timeout     time.Duration `env:"TIMEOUT" default:"5m" help:"Longest time a request can take before it is cancelled. 0 = no limit"`
+	MaxRequests int           `env:"MAX_REQUESTS" default:"4" help:"Number of requests handled at the same time. Further requests are answered with 503 Service Unavailable"`
+	MaxThreads  int           `env:"MAX_THREADS" default:"1" help:"Largest number of threads a request can use with the Threads option. Requests asking for more threads are answered with 400 Bad Request"`
+
+	server *server.Server
+}
+
+// Validate server input
+func (c *serveCmd) Validate() error {
+	maxBodySize, err := bed.ParseMemorySize(c.MaxBodySize)
+	if err != nil {
+		return fmt.Errorf("--max-body-size %v", err)
+	}
+	c.server, err = server.New(server.Config{
+		MaxBodySize: int64(maxBodySize),
+		Timeout:     c.Timeout,
+		MaxRequests: c.MaxRequests,
+		MaxThreads:  c.MaxThreads,
+	})
+	return err
+}
+
+// Serve until the process is interrupted or terminated, and
+// let the requests being handled finish before stopping
+func (c *serveCmd) run() (error, string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{
+		Addr:              c.Addr,
+		Handler:           c.server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "listening on %s\n", c.Addr)
+	select {
+	case err := <-serveErr:
+		return err, "while serving"
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), c.Timeout+10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err, "while stopping the server"
+	}
+	return nil, ""
+}
+
+// Sort and write bed file
+func sortAndWrite(bf *bed.Bedfile) (error, string) {
 	// Sort
-	if err := s.Bedfile.Sort(); err != nil {
+	if err := bf.Sort(); err != nil {
 		return err, "while sorting"
 	}
 	// Write output
-	if err := s.Bedfile.Write(); err != nil {
+	if err := bf.Write(); err != nil {
 		return err, "while writing"
 	}
 	return nil, ""
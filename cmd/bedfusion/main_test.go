@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/go-test/deep"
+
+	"github.com/hbesfb/bedfusion/internal/bed"
+)
+
+func TestConfigFile(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		args            []string
+		selectedBedfile func(s *session) bed.Bedfile
+		expectedCommand string
+	}
+	testCases := []testCase{
+		{
+			testing:         "default command",
+			args:            []string{"-c", "../../examples/config-test.yml", "../../examples/sort-test.bed"},
+			selectedBedfile: func(s *session) bed.Bedfile { return s.Fuse.Bedfile },
+			expectedCommand: "fuse <inputs>",
+		},
+		{
+			testing:         "intersect command",
+			args:            []string{"-c", "../../examples/config-test.yml", "intersect", "../../examples/sort-test.bed", "-b", "../../examples/sort-test.bed"},
+			selectedBedfile: func(s *session) bed.Bedfile { return s.Intersect.Bedfile },
+			expectedCommand: "intersect <inputs>",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var s session
+			parser, err := kong.New(&s, kongOptions()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, err := parser.Parse(tc.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tc.expectedCommand, ctx.Command()); diff != nil {
+				t.Error("expected VS received command", diff)
+			}
+			bf := tc.selectedBedfile(&s)
+			received := []any{bf.StrandCol, bf.FeatCol, bf.SortType, bf.ChrOrder, bf.Overlap, bf.NoMerge}
+			// The columns are 0-based after the input is verified
+			expected := []any{3, 4, bed.CcsST, []string{"x", "y", "mt"}, 10, false}
+			if diff := deep.Equal(expected, received); diff != nil {
+				t.Error("expected VS received options from the configuration file", diff)
+			}
+		})
+	}
+}
+
+func TestLintExitCode(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing          string
+		content          string
+		expectedExitCode int
+	}
+	testCases := []testCase{
+		{
+			testing:          "no problems",
+			content:          "1\t1\t4\n",
+			expectedExitCode: 0,
+		},
+		{
+			testing:          "warnings",
+			content:          "1\t4\t4\n",
+			expectedExitCode: 3,
+		},
+		{
+			testing:          "errors",
+			content:          "1\t8\t4\n",
+			expectedExitCode: 4,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			input := filepath.Join(dir, "input.bed")
+			if err := os.WriteFile(input, []byte(tc.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var s session
+			parser, err := kong.New(&s, kongOptions()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := parser.Parse([]string{"lint", input, "-o", filepath.Join(dir, "report.txt")}); err != nil {
+				t.Fatal(err)
+			}
+			err, _ = s.Lint.run()
+			exitCode := 0
+			var exitCodeErr exitCodeError
+			if errors.As(err, &exitCodeErr) {
+				exitCode = int(exitCodeErr)
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tc.expectedExitCode, exitCode); diff != nil {
+				t.Error("expected VS received exit code", diff)
+			}
+		})
+	}
+}
+
+func TestComplementSortType(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		args       []string
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "default sort type",
+			args:    []string{},
+		},
+		{
+			testing: "fasta index sort type",
+			args:    []string{"--sort-type", bed.FidxST},
+		},
+		{
+			testing:    "natural sort type",
+			args:       []string{"--sort-type", bed.NatST},
+			shouldFail: true,
+		},
+		{
+			testing:    "lexicographic sort type given as a flag",
+			args:       []string{"-s", bed.LexST},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var s session
+			parser, err := kong.New(&s, kongOptions()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := append([]string{"complement", "../../examples/sort-test.bed", "-f", "../../examples/test.fasta.fai"}, tc.args...)
+			_, err = parser.Parse(args)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail && s.Complement.Bedfile.SortType != bed.FidxST {
+				t.Errorf("expected sort type %s, but got %s", bed.FidxST, s.Complement.Bedfile.SortType)
+			}
+		})
+	}
+}
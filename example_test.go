@@ -0,0 +1,89 @@
+package bedfusion_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hbesfb/bedfusion"
+)
+
+func Example() {
+	fastaIdx := "1\t249250621\n10\t135534747\n"
+	bedFile := "1\t1\t4\n1\t5\t9\n10\t5\t8\n1\t20\t30\n"
+
+	bf, err := bedfusion.New(bedfusion.Options{
+		FastaIdx: &bedfusion.Input{Name: "test.fasta.fai", Reader: strings.NewReader(fastaIdx)},
+		Padding:  5,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Read("padding-test.bed", strings.NewReader(bedFile)); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Fuse(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Write(os.Stdout); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 1	0	35
+	// 10	0	13
+}
+
+func ExampleBedfile_Lines() {
+	bedFile := "1\t10\t20\t+\tA\n1\t15\t30\t+\tB\n1\t40\t50\t-\tC\n"
+
+	bf, err := bedfusion.New(bedfusion.Options{
+		StrandCol: 4,
+		ColOps:    []string{"5:collapse"},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Read("stranded.bed", strings.NewReader(bedFile)); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Fuse(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, l := range bf.Lines() {
+		fmt.Println(l.Chr, l.Start, l.Stop, l.Strand, l.Fields[4])
+	}
+	// Output:
+	// 1 10 30 + A,B
+	// 1 40 50 - C
+}
+
+func ExampleBedfile_Sort() {
+	bedFile := "10\t5\t8\n2\t1\t4\n1\t20\t30\n"
+
+	bf, err := bedfusion.New(bedfusion.Options{SortType: bedfusion.NatST})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Read("sort-test.bed", strings.NewReader(bedFile)); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Sort(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bf.Write(os.Stdout); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 1	20	30
+	// 2	1	4
+	// 10	5	8
+}
@@ -0,0 +1,158 @@
+package bed
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Input formats
+var BedIF = "bed"   // bed file
+var GFF3IF = "gff3" // GFF3 annotation file, attributes given as key=value
+var GTFIF = "gtf"   // GTF (GFF2) annotation file, attributes given as key "value"
+
+// Annotation file (GFF3 and GTF) constants
+const (
+	seqidAIdx      = 0
+	typeAIdx       = 2
+	startAIdx      = 3
+	endAIdx        = 4
+	strandAIdx     = 6
+	attributesAIdx = 8
+	nrOfAnnotCols  = 9
+)
+
+// Returns true if the input is an annotation file
+func (bf Bedfile) isAnnotation() bool {
+	return bf.InputFormat == GFF3IF || bf.InputFormat == GTFIF
+}
+
+// Verify annotation options and add --feat-attr to the attributes
+//
+// Must be run before the columns are verified, as --feat-attr
+// sets --feat-col to the column of the attribute
+func (bf *Bedfile) verifyAndHandleAnnotation() error {
+	if !bf.isAnnotation() {
+		if len(bf.FeatureType) > 0 || len(bf.AttrFilter) > 0 || len(bf.Attr) > 0 || bf.FeatAttr != "" {
+			return fmt.Errorf("--feature-type, --attr-filter, --attr and --feat-attr must be used together with --input-format=%s or --input-format=%s", GFF3IF, GTFIF)
+		}
+		return nil
+	}
+	bf.attrFilters = map[string][]string{}
+	for _, filter := range bf.AttrFilter {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("--attr-filter must be given as <attribute>=<value>: %s", filter)
+		}
+		bf.attrFilters[key] = append(bf.attrFilters[key], value)
+	}
+	if bf.FeatAttr != "" {
+		if bf.FeatCol != 0 {
+			return fmt.Errorf("--feat-attr can not be used together with --feat-col")
+		}
+		idx := slices.Index(bf.Attr, bf.FeatAttr)
+		if idx == -1 {
+			bf.Attr = append(bf.Attr, bf.FeatAttr)
+			idx = len(bf.Attr) - 1
+		}
+		// The attributes are written after chr, start, stop and strand
+		bf.FeatCol = idx + 5
+	}
+	return nil
+}
+
+// Convert an annotation line to a bed line
+//
+// The coordinates are converted from 1-based closed to 0-based half-open,
+// and the bed line contains chr, start, stop and strand followed by the
+// attributes given by --attr. Returns false if the line is a comment or
+// is removed by --feature-type or --attr-filter.
+func (bf Bedfile) annotationToBed(lineText string, lineNr int) (string, bool, error) {
+	if lineText == "" || strings.HasPrefix(lineText, "#") {
+		return "", false, nil
+	}
+	cols := strings.Split(lineText, "\t")
+	if len(cols) != nrOfAnnotCols {
+		return "", false, fmt.Errorf("expected %d columns on line %d got %d: %s",
+			nrOfAnnotCols, lineNr, len(cols), lineText)
+	}
+	// Filter on feature type
+	if len(bf.FeatureType) > 0 && !slices.Contains(bf.FeatureType, cols[typeAIdx]) {
+		return "", false, nil
+	}
+	attributes, err := bf.parseAttributes(cols[attributesAIdx])
+	if err != nil {
+		return "", false, fmt.Errorf("%v on line %d", err, lineNr)
+	}
+	// Filter on attributes, the values for the same attribute
+	// are alternatives while all attributes must match
+	for key, values := range bf.attrFilters {
+		if !slices.ContainsFunc(attributes[key], func(v string) bool {
+			return slices.Contains(values, v)
+		}) {
+			return "", false, nil
+		}
+	}
+	start, err := strconv.Atoi(cols[startAIdx])
+	if err != nil {
+		return "", false, fmt.Errorf("non-int start position on line %d: %s", lineNr, cols[startAIdx])
+	}
+	end, err := strconv.Atoi(cols[endAIdx])
+	if err != nil {
+		return "", false, fmt.Errorf("non-int end position on line %d: %s", lineNr, cols[endAIdx])
+	}
+	strand := cols[strandAIdx]
+	if strand == "?" {
+		strand = "."
+	}
+	bedCols := []string{cols[seqidAIdx], strconv.Itoa(start - 1), strconv.Itoa(end), strand}
+	for _, attr := range bf.Attr {
+		value := "."
+		if values, ok := attributes[attr]; ok {
+			value = strings.Join(values, ",")
+		}
+		bedCols = append(bedCols, value)
+	}
+	return strings.Join(bedCols, "\t"), true, nil
+}
+
+// Parse the attribute column of a GFF3 or GTF line
+//
+// Attributes given several times, or with several comma
+// separated values in GFF3, are returned in the order given
+func (bf Bedfile) parseAttributes(col string) (map[string][]string, error) {
+	attributes := map[string][]string{}
+	if col == "." {
+		return attributes, nil
+	}
+	for _, attr := range strings.Split(col, ";") {
+		attr = strings.TrimSpace(attr)
+		if attr == "" {
+			continue
+		}
+		switch bf.InputFormat {
+		case GFF3IF:
+			key, value, ok := strings.Cut(attr, "=")
+			if !ok {
+				return nil, fmt.Errorf("unexpected GFF3 attribute format: %s", attr)
+			}
+			for _, v := range strings.Split(value, ",") {
+				unescaped, err := url.PathUnescape(v)
+				if err != nil {
+					return nil, fmt.Errorf("unexpected GFF3 attribute format: %s", attr)
+				}
+				attributes[key] = append(attributes[key], unescaped)
+			}
+		case GTFIF:
+			key, value, ok := strings.Cut(attr, " ")
+			if !ok {
+				return nil, fmt.Errorf("unexpected GTF attribute format: %s", attr)
+			}
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			attributes[key] = append(attributes[key], value)
+		}
+	}
+	return attributes, nil
+}
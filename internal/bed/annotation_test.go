@@ -0,0 +1,218 @@
+package bed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+var testGTF = strings.Join([]string{
+	"#!genome-build GRCh38.p14",
+	"1\tensembl\tgene\t1000\t2000\t.\t+\t.\tgene_id \"ENSG01\"; gene_name \"GENEA\";",
+	"1\tensembl\texon\t1000\t1100\t.\t+\t.\tgene_id \"ENSG01\"; transcript_id \"ENST01\"; gene_name \"GENEA\"; tag \"basic\"; tag \"Ensembl_canonical\";",
+	"1\tensembl\texon\t1500\t2000\t.\t+\t.\tgene_id \"ENSG01\"; transcript_id \"ENST02\"; gene_name \"GENEA\"; tag \"basic\";",
+	"2\tensembl\texon\t10\t20\t.\t-\t.\tgene_id \"ENSG02\"; transcript_id \"ENST03\"; gene_name \"GENEB\"; tag \"Ensembl_canonical\";",
+}, "\n")
+
+var testGFF3 = strings.Join([]string{
+	"##gff-version 3",
+	"1\tRefSeq\tgene\t1000\t2000\t.\t+\t.\tID=gene-A;Name=GENE%3BA;tag=basic,MANE Select",
+	"1\tRefSeq\texon\t1000\t1100\t.\t?\t.\tID=exon-1;Parent=rna-1",
+	"##FASTA",
+	">1",
+	"ACGT",
+}, "\n")
+
+func TestVerifyAndHandleAnnotation(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		bf         Bedfile
+		expectedBf Bedfile
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing:    "bed input",
+			bf:         Bedfile{InputFormat: BedIF},
+			expectedBf: Bedfile{InputFormat: BedIF},
+		},
+		{
+			testing:    "annotation options with bed input",
+			bf:         Bedfile{InputFormat: BedIF, FeatureType: []string{"exon"}},
+			shouldFail: true,
+		},
+		{
+			testing: "attribute filters",
+			bf: Bedfile{
+				InputFormat: GTFIF,
+				AttrFilter:  []string{"tag=basic", "gene_name=A", "tag=MANE Select"},
+			},
+			expectedBf: Bedfile{
+				InputFormat: GTFIF,
+				AttrFilter:  []string{"tag=basic", "gene_name=A", "tag=MANE Select"},
+				attrFilters: map[string][]string{
+					"tag":       {"basic", "MANE Select"},
+					"gene_name": {"A"},
+				},
+			},
+		},
+		{
+			testing:    "attribute filter without value",
+			bf:         Bedfile{InputFormat: GTFIF, AttrFilter: []string{"tag"}},
+			shouldFail: true,
+		},
+		{
+			testing: "feature attribute added to attributes",
+			bf:      Bedfile{InputFormat: GFF3IF, Attr: []string{"Name"}, FeatAttr: "ID"},
+			expectedBf: Bedfile{
+				InputFormat: GFF3IF, Attr: []string{"Name", "ID"}, FeatAttr: "ID",
+				FeatCol: 6, attrFilters: map[string][]string{},
+			},
+		},
+		{
+			testing: "feature attribute already in attributes",
+			bf:      Bedfile{InputFormat: GFF3IF, Attr: []string{"Name", "ID"}, FeatAttr: "Name"},
+			expectedBf: Bedfile{
+				InputFormat: GFF3IF, Attr: []string{"Name", "ID"}, FeatAttr: "Name",
+				FeatCol: 5, attrFilters: map[string][]string{},
+			},
+		},
+		{
+			testing:    "feature attribute together with feature column",
+			bf:         Bedfile{InputFormat: GFF3IF, FeatAttr: "Name", FeatCol: 5},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bf.verifyAndHandleAnnotation()
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedBf, tc.bf); diff != nil {
+					t.Error("expected VS received bed", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestReadAnnotation(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bf            Bedfile
+		content       string
+		expectedLines []Line
+		shouldFail    bool
+	}
+	testCases := []testCase{
+		{
+			testing: "gtf without filters",
+			bf:      Bedfile{InputFormat: GTFIF},
+			content: testGTF,
+			expectedLines: []Line{
+				{Chr: "1", Start: 999, Stop: 2000, Opt: []byte("+")},
+				{Chr: "1", Start: 999, Stop: 1100, Opt: []byte("+")},
+				{Chr: "1", Start: 1499, Stop: 2000, Opt: []byte("+")},
+				{Chr: "2", Start: 9, Stop: 20, Opt: []byte("-")},
+			},
+		},
+		{
+			testing: "gtf with feature type, attribute filter and attributes",
+			bf: Bedfile{
+				InputFormat: GTFIF,
+				FeatureType: []string{"exon"},
+				AttrFilter:  []string{"tag=Ensembl_canonical"},
+				Attr:        []string{"transcript_id", "tag", "exon_number"},
+				StrandCol:   4,
+				FeatAttr:    "gene_name",
+			},
+			content: testGTF,
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 999, Stop: 1100, Strand: "+", Feat: "GENEA",
+					Opt: []byte("+\tENST01\tbasic,Ensembl_canonical\t.\tGENEA"),
+				},
+				{
+					Chr: "2", Start: 9, Stop: 20, Strand: "-", Feat: "GENEB",
+					Opt: []byte("-\tENST03\tEnsembl_canonical\t.\tGENEB"),
+				},
+			},
+		},
+		{
+			testing: "gtf with several values for the same attribute filter",
+			bf: Bedfile{
+				InputFormat: GTFIF,
+				AttrFilter:  []string{"transcript_id=ENST02", "transcript_id=ENST03"},
+			},
+			content: testGTF,
+			expectedLines: []Line{
+				{Chr: "1", Start: 1499, Stop: 2000, Opt: []byte("+")},
+				{Chr: "2", Start: 9, Stop: 20, Opt: []byte("-")},
+			},
+		},
+		{
+			testing: "gff3 with attributes and sequences",
+			bf:      Bedfile{InputFormat: GFF3IF, Attr: []string{"Name", "tag"}},
+			content: testGFF3,
+			expectedLines: []Line{
+				{Chr: "1", Start: 999, Stop: 2000, Opt: []byte("+\tGENE;A\tbasic,MANE Select")},
+				{Chr: "1", Start: 999, Stop: 1100, Opt: []byte(".\t.\t.")},
+			},
+		},
+		{
+			testing: "gff3 with attribute filter containing space",
+			bf:      Bedfile{InputFormat: GFF3IF, AttrFilter: []string{"tag=MANE Select"}},
+			content: testGFF3,
+			expectedLines: []Line{
+				{Chr: "1", Start: 999, Stop: 2000, Opt: []byte("+")},
+			},
+		},
+		{
+			testing:    "wrong number of columns",
+			bf:         Bedfile{InputFormat: GTFIF},
+			content:    "1\tensembl\texon\t1000\t1100\t.\t+\t.",
+			shouldFail: true,
+		},
+		{
+			testing:    "non-int end",
+			bf:         Bedfile{InputFormat: GTFIF},
+			content:    "1\tensembl\texon\t1000\tx\t.\t+\t.\tgene_id \"A\";",
+			shouldFail: true,
+		},
+		{
+			testing:    "gff3 attribute without value",
+			bf:         Bedfile{InputFormat: GFF3IF},
+			content:    "1\tRefSeq\texon\t1000\t1100\t.\t+\t.\tID",
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			tc.bf.Threads = 1 // default of --threads
+			if err := tc.bf.VerifyAndHandle(); err != nil {
+				t.Fatal(err)
+			}
+			err := tc.bf.readBed(strings.NewReader(tc.content))
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedLines, tc.bf.Lines); diff != nil {
+					t.Error("expected VS received lines", diff)
+				}
+				if diff := deep.Equal([]string(nil), tc.bf.Header); diff != nil {
+					t.Error("expected VS received header", diff)
+				}
+			}
+		})
+	}
+}
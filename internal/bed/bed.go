@@ -2,62 +2,188 @@ package bed
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 // Note that the the user will give the columns with 1-based indexing,
 // but that we convert this to zero-based indexing in .VerifyAndHandle()
 type Bedfile struct {
-	Inputs   []string `arg:"" help:"Bed file path(s). If more than one is provided the files will be joined as if they were one file"`
+	Inputs   []string `arg:"" optional:"" help:"Bed file path(s). If more than one is provided the files will be joined as if they were one file. Gzip and BGZF compressed files are decompressed automatically"`
 	Output   string   `env:"OUTPUT_FILE" short:"o" help:"Path to the output file. If unset the output will be written to stdout"`
-	FastaIdx string   `env:"FASTA_IDX" short:"f" help:"Tab separated file containing at least two columns where the first column contains the chromosome and the second it's size. Compatible with fasta index files, but any text file can be used as long as the file conditions are met"`
+	FastaIdx string   `env:"FASTA_IDX" short:"f" aliases:"genome" help:"Genome file with the chromosome sizes. Either a tab separated file containing at least two columns where the first column contains the chromosome and the second it's size (e.g. a fasta index file or a UCSC chrom.sizes file), a Picard/GATK sequence dictionary (.dict), a SAM file or a BAM file. The format is detected from the content of the file. Gzip and BGZF compressed files are decompressed automatically"`
+	Fasta    string   `env:"FASTA" help:"Fasta file that is scanned to find the chromosome sizes and order, for when there is no fasta index file. Can not be used together with --fasta-idx. Gzip and BGZF compressed files are decompressed automatically"`
+	WriteFai bool     `env:"WRITE_FAI" help:"Write a samtools compatible fasta index file (<fasta>.fai) next to --fasta, so that later runs can use it with --fasta-idx. Fails if the lines of a sequence have different lengths or if the fasta file is compressed"`
 
-	StrandCol int `env:"STRAND_COL" group:"input" help:"The column containing the strand information (1-based column index). If this option is set regions on the same strand will not be merged"`
-	FeatCol   int `env:"FEAT_COL" group:"input" help:"The column containing the feature (e.g. gene id, transcript id etc.) information (1-based column index). If this option is set regions on the same feature will not be merged"`
+	StrandCol   int      `env:"STRAND_COL" group:"input" help:"The column containing the strand information (1-based column index). If this option is set regions on the same strand will not be merged"`
+	FeatCol     int      `env:"FEAT_COL" group:"input" help:"The column containing the feature (e.g. gene id, transcript id etc.) information (1-based column index). If this option is set regions on the same feature will not be merged"`
+	Presorted   bool     `env:"PRESORTED" group:"input" help:"The input is already sorted by chromosome (according to --sort-type) and start. Regions are padded, merged and written one by one instead of reading the whole input into memory. Unsorted input will result in an error"`
+	ChrAlias    string   `env:"CHR_ALIAS" group:"input" help:"Tab separated chromosome alias file in the format of the UCSC chromAlias.txt files, where each line contains the names of one chromosome and the optional header line (starting with #) names the naming style of each column. The chromosome names in the bed files and the fasta index file are converted to the naming style chosen by --chr-naming before padding, merging and sorting"`
+	ChrAliasSet string   `env:"CHR_ALIAS_SET" group:"input" enum:",${grch37CA},${grch38CA}" default:"" help:"Built-in chromosome alias set to use instead of --chr-alias. ${grch37CA} = GRCh37/hg19, ${grch38CA} = GRCh38/hg38. Both sets contain the primary chromosomes with the naming styles ucsc (chr1, chrM), ensembl (1, MT) and refseq (NC_000001.11). In ${grch37CA} chrM has no alias, as the hg19 chrM is a different sequence than the GRCh37 MT"`
+	ChrNaming   string   `env:"CHR_NAMING" group:"input" help:"The naming style (a column name in the header of the alias file) to convert the chromosome names to. If unset the names in the first column of the alias file are used"`
+	Bed12       bool     `env:"BED12" name:"bed12" group:"input" help:"The input is in the BED12 format. When merging, the blocks (e.g. exons) are united and the thick region spans all thick regions, and when padding the outermost blocks are extended, so that the output stays valid BED12. The score and itemRgb columns keep the value of the first region unless --col-op is set, and --strand-col is set to column 6 if it is not set"`
+	Schema      int      `env:"SCHEMA" group:"input" help:"Project every input line to this number of columns (e.g. 3, 4 or 6 for BED3, BED4 or BED6), truncating longer lines and filling missing columns with --schema-fill, so that files with different numbers of columns can be joined"`
+	SchemaFill  string   `env:"SCHEMA_FILL" group:"input" default:"." help:"Value of the columns added by --schema and --col-map"`
+	ColMap      []string `env:"COL_MAP" group:"input" sep:"none" help:"Column mapping of one input file, given as <file>=<col>,<col>,... where each column is the 1-based column of the file used as the next output column, or . for a column filled with --schema-fill (e.g. vendor.bed=1,2,3,.,5,4). Can be repeated for several files. The files are projected to the width of the mapping, and the other files to --schema"`
+	InputFormat string   `env:"INPUT_FORMAT" group:"input" enum:"${bedIF},${gff3IF},${gtfIF},${vcfIF},${bedpeIF}" default:"${bedIF}" help:"Format of the input files. ${bedIF} = bed files, ${gff3IF} = GFF3 annotation files, ${gtfIF} = GTF annotation files, ${vcfIF} = VCF files, ${bedpeIF} = BEDPE files where each line is a pair of regions. Annotation features are converted to regions with the columns chr, start, stop and strand followed by the attributes given by --attr. VCF records are converted to regions spanning the REF allele with the columns chr, start, stop and ID followed by the INFO fields given by --info-field. The coordinates are converted to 0-based half-open. BEDPE pairs are padded, merged and sorted by both regions, see docs/bedpe-files.md"`
+
+	FeatureType []string `env:"FEATURE_TYPE" group:"annotation" help:"Comma separated feature types (third column of the annotation file, e.g. exon or CDS) to keep. If unset all features are kept"`
+	AttrFilter  []string `env:"ATTR_FILTER" group:"annotation" help:"Only keep features with the given attribute value, given as <attribute>=<value> (e.g. gene_name=BRCA2 or tag=Ensembl_canonical). Can be repeated or comma separated. Features must match all attributes, but only one of the values given for the same attribute"`
+	Attr        []string `env:"ATTR" group:"annotation" help:"Comma separated attributes (e.g. gene_name,transcript_id) written as optional columns after the strand column (column 4), in the given order. Missing attributes are written as a dot"`
+	FeatAttr    string   `env:"FEAT_ATTR" group:"annotation" help:"Attribute to use as the feature, so that only features with the same value are merged (see --feat-col). The attribute is added to --attr if it is not already there"`
+
+	InfoField []string `env:"INFO_FIELD" group:"vcf" help:"Comma separated INFO fields (e.g. AF,GENE) written as optional columns after the ID column (column 4), in the given order. Missing fields are written as . and flags as 1"`
 
 	SortType    string   `env:"SORT_TYPE" group:"sorting" enum:"${lexST},${natST},${ccsST},${fidxST}" default:"${lexST}" short:"s" help:"How the bed file should be sorted. ${lexST} = lexicographic sorting (chr: 1 < 10 < 2 < MT < X), ${natST} = natural sorting (chr: 1 < 2 < 10 < MT < X), ${ccsST} = custom chromosome sorting (see --chr-order flag ), ${fidxST} = use ordering from fasta index file (must be used together with --fasta-idx)"`
 	ChrOrder    []string `env:"CHR_ORDER" group:"sorting" help:"Comma separated custom chromosome order, to be used with custom chromosome sorting (--sort-type=ccs). Chromosomes not on the list will be sorted naturally after the ones in the list"`
 	Deduplicate bool     `env:"DEDUPLICATE" group:"sorting" cmd:"" short:"d" help:"Remove duplicated lines"`
+	MaxMemory   string   `env:"MAX_MEMORY" group:"sorting" help:"Approximate memory budget for the regions kept in memory (e.g. 500M or 4G). If set the regions are sorted in chunks that are written to --tmp-dir and merged afterwards, so that files larger than the memory budget can be handled"`
+	TmpDir      string   `env:"TMP_DIR" group:"sorting" help:"Directory for the temporary files used together with --max-memory. If unset the default directory for temporary files is used"`
+	Threads     int      `env:"THREADS" group:"sorting" default:"1" help:"Number of chromosomes that are padded, merged and sorted concurrently. The output is the same regardless of the number of threads. Not used together with --presorted or --max-memory"`
+
+	NoMerge bool     `env:"NO_MERGE" group:"merging" cmd:"" help:"Do not merge regions"`
+	Overlap int      `env:"OVERLAP" group:"merging" default:"0" help:"Overlap between regions to be merged. Note that touching regions are merged (e.g. if two regions are on the same chr, and the overlap is they will be merged if one ends at 5 and the other starts at 6). If you don't want touching regions to be merged set overlap to -1"`
+	ColOp   []string `env:"COL_OP" group:"merging" help:"Operation used to combine the values of an optional column when regions are merged, given as <column>:<operation> (1-based column index, e.g. --col-op 5:sum). Can be repeated or comma separated for several columns. Operations: sum, mean, min, max, median, count, count_distinct, collapse (all values), distinct (unique values), first and last. Columns without an operation are joined as a comma separated list of unique values"`
 
-	NoMerge bool `env:"NO_MERGE" group:"merging" cmd:"" help:"Do not merge regions"`
-	Overlap int  `env:"OVERLAP" group:"merging" default:"0" help:"Overlap between regions to be merged. Note that touching regions are merged (e.g. if two regions are on the same chr, and the overlap is they will be merged if one ends at 5 and the other starts at 6). If you don't want touching regions to be merged set overlap to -1"`
+	Padding       int    `env:"PADDING" group:"padding" short:"p" help:"Padding in bp. Note that padding is done before merging"`
+	PaddingType   string `env:"PADDING_TYPE" group:"padding" enum:"${failPT},${warnPT},${forcePT}" default:"${failPT}" help:"Padding type. safe = bedfusion will fail if it encounters a chromosome not in the fasta index file, ${warnPT} = will only pad regions in the fasta index file and give a warning about chromosomes not in the fasta index file, ${forcePT} = will pad regardless, if --fasta-idx is set there will be given a warning about the chromosomes not in the fasta index file, if --fasta-idx is not set no warnings will be given"`
+	FirstBase     int    `env:"FIRST_BASE" group:"padding" default:"0" help:"The start coordinate of the first base on each chromosome"`
+	PadUpstream   int    `env:"PAD_UPSTREAM" group:"padding" help:"Padding in bp added upstream of the regions, meaning before the start on the plus strand and after the stop on the minus strand (- or -1). Must be used together with --strand-col"`
+	PadDownstream int    `env:"PAD_DOWNSTREAM" group:"padding" help:"Padding in bp added downstream of the regions, meaning after the stop on the plus strand and before the start on the minus strand (- or -1). Must be used together with --strand-col"`
+	PadLeft       int    `env:"PAD_LEFT" group:"padding" help:"Padding in bp added before the start of the regions regardless of strand"`
+	PadRight      int    `env:"PAD_RIGHT" group:"padding" help:"Padding in bp added after the stop of the regions regardless of strand"`
 
-	Padding     int    `env:"PADDING" group:"padding" short:"p" help:"Padding in bp. Note that padding is done before merging"`
-	PaddingType string `env:"PADDING_TYPE" group:"padding" enum:"${failPT},${warnPT},${forcePT}" default:"${failPT}" help:"Padding type. safe = bedfusion will fail if it encounters a chromosome not in the fasta index file, ${warnPT} = will only pad regions in the fasta index file and give a warning about chromosomes not in the fasta index file, ${forcePT} = will pad regardless, if --fasta-idx is set there will be given a warning about the chromosomes not in the fasta index file, if --fasta-idx is not set no warnings will be given"`
-	FirstBase   int    `env:"FIRST_BASE" group:"padding" default:"0" help:"The start coordinate of the first base on each chromosome"`
+	Bgzip         bool   `env:"BGZIP" group:"output" help:"Compress the output in the BGZF format (compatible with bgzip)"`
+	HeaderPolicy  string `env:"HEADER_POLICY" group:"output" enum:"${firstHP},${concatHP},${dropHP},${trackHP}" default:"${firstHP}" help:"How the header lines (browser, track and # lines before the first region) of the input files are handled. ${firstHP} = keep the header lines of the first file, ${concatHP} = keep the header lines of all files without duplicates, ${dropHP} = drop all header lines, ${trackHP} = replace the header lines with a generated track line (see --track-line). Browser and track lines after the first region are handled as header lines"`
+	TrackLine     string `env:"TRACK_LINE" group:"output" help:"Track line written with --header-policy=${trackHP}. If unset the track line is named after the output file, or bedfusion if the output is written to stdout"`
+	CommentPolicy string `env:"COMMENT_POLICY" group:"output" enum:"${keepCP},${topCP},${dropCP}" default:"${keepCP}" help:"How comment lines (# lines after the first region of a file) are handled. ${keepCP} = keep the comments in front of the region following them, ${topCP} = move the comments to the top, after the header lines, ${dropCP} = drop the comments"`
+	Tabix         bool   `env:"TABIX" group:"output" help:"Write a tabix index next to the output file (<output>.tbi, or <output>.csi if regions end beyond 512 Mbp). Must be used together with --bgzip and --output"`
 
 	Header       []string `kong:"-"`
 	Lines        []Line   `kong:"-"`
+	Pairs        []Pair   `kong:"-"`
 	chrOrderMap  map[string]int
 	chrLengthMap map[string]int
+	maxMemory    int
+	colOps       map[int]string
+	chrAliases   map[string]string
+	chrNoAlias   map[string]bool
+	attrFilters  map[string][]string
+	warnings     io.Writer
+	comments     []string
+	colMaps      map[string][]int
+	currentInput string
+	strs         map[string]string
 }
 
+// Region of a bed file
+//
+// The chromosome, strand and feature are interned (see .intern()), and
+// the optional columns (the columns after stop) are kept as tab separated
+// text that is only split when needed, so that large files use as little
+// memory as possible. All columns are returned by .Full()
 type Line struct {
-	Chr    string
-	Start  int
-	Stop   int
-	Strand string
-	Feat   string
-	Full   []string
+	Chr      string
+	Start    int
+	Stop     int
+	Strand   string
+	Feat     string
+	Opt      []byte // nil if the line only has chr, start and stop
+	Comments []string
 }
 
 // Verifies and handles Bedfile input
 func (bf *Bedfile) VerifyAndHandle() error {
+	if err := bf.verifyAndHandleAnnotation(); err != nil {
+		return err
+	}
+	if err := bf.verifyVcf(); err != nil {
+		return err
+	}
+	if err := bf.verifyBedpe(); err != nil {
+		return err
+	}
 	if err := bf.verifyAndHandleColumns(); err != nil {
 		return err
 	}
+	if err := bf.verifyAndHandleColOps(); err != nil {
+		return err
+	}
+	if err := bf.verifyAndHandleBed12(); err != nil {
+		return err
+	}
+	if err := bf.verifyAndHandleSchema(); err != nil {
+		return err
+	}
+	if err := bf.verifyPaddingCombinations(); err != nil {
+		return err
+	}
+	if err := bf.verifyChrAliasCombinations(); err != nil {
+		return err
+	}
+	if err := bf.verifyAndHandleFasta(); err != nil {
+		return err
+	}
 	if err := bf.verifyFastaIdxCombinations(); err != nil {
 		return err
 	}
 	if err := bf.verifyFirstBase(); err != nil {
 		return err
 	}
+	if err := bf.verifyThreads(); err != nil {
+		return err
+	}
+	if err := bf.verifyAndHandleMaxMemory(); err != nil {
+		return err
+	}
+	if err := bf.verifyOutputCombinations(); err != nil {
+		return err
+	}
+	if err := bf.verifyAndHandleHeaderPolicies(); err != nil {
+		return err
+	}
 	bf.handleCCSSorting()
 	bf.cleanPaths()
 	return nil
 }
 
+// Set where warnings are written, if unset they
+// are written to standard error
+func (bf *Bedfile) SetWarningWriter(w io.Writer) {
+	bf.warnings = w
+}
+
+// Write warning to the warning writer
+func (bf Bedfile) warn(format string, a ...any) {
+	w := bf.warnings
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "warning: "+format+"\n", a...)
+}
+
+// Name of the source of the chromosome lengths used in messages
+func (bf Bedfile) chrLengthsName() string {
+	if bf.FastaIdx == "" && bf.InputFormat == VcfIF {
+		return "the ##contig lines of the vcf files"
+	}
+	if bf.Fasta != "" {
+		return "fasta file " + bf.Fasta
+	}
+	return "fasta index file " + bf.FastaIdx
+}
+
+// Verify that at least one input bed file is given
+func (bf Bedfile) VerifyInputs() error {
+	if len(bf.Inputs) == 0 {
+		return fmt.Errorf("at least one input bed file must be given")
+	}
+	return nil
+}
+
 // Verifies Strand and Feat columns and subtracts 1 to be able to use zero-based indexing
 func (bf *Bedfile) verifyAndHandleColumns() error {
 	if bf.StrandCol != 0 {
@@ -79,9 +205,16 @@ func (bf *Bedfile) verifyAndHandleColumns() error {
 }
 
 // Verify fasta-idx combinations
+//
+// VCF files can give the chromosome lengths in their ##contig lines
+// instead of the fasta index file, this is verified when they are
+// read (see .VerifyVcfChrLengths())
 func (bf Bedfile) verifyFastaIdxCombinations() error {
+	if bf.InputFormat == VcfIF {
+		return nil
+	}
 	// Verify that fasta-idx is set if padding is selected
-	if bf.Padding != 0 && bf.PaddingType != "force" && bf.FastaIdx == "" {
+	if bf.HasPadding() && bf.PaddingType != "force" && bf.FastaIdx == "" {
 		return fmt.Errorf("--padding-type=%s must be used together with --fasta-idx", bf.PaddingType)
 	}
 	// Verify that fasta-idx is set if sort type is fastaidx
@@ -91,6 +224,39 @@ func (bf Bedfile) verifyFastaIdxCombinations() error {
 	return nil
 }
 
+// Verify padding combinations
+func (bf Bedfile) verifyPaddingCombinations() error {
+	stranded := bf.PadUpstream != 0 || bf.PadDownstream != 0
+	unstranded := bf.PadLeft != 0 || bf.PadRight != 0
+	if bf.Padding != 0 && (stranded || unstranded) {
+		return fmt.Errorf("--padding can not be used together with --pad-upstream, --pad-downstream, --pad-left or --pad-right")
+	}
+	if stranded && unstranded {
+		return fmt.Errorf("--pad-upstream and --pad-downstream can not be used together with --pad-left or --pad-right")
+	}
+	// The strands of BEDPE files are always read from column 9 and 10
+	if stranded && bf.StrandCol == 0 && bf.InputFormat != BedpeIF {
+		return fmt.Errorf("--pad-upstream and --pad-downstream must be used together with --strand-col")
+	}
+	// Regions on different strands are padded differently, so the padded
+	// regions are not necessarily sorted even if the input is
+	if stranded && bf.PadUpstream != bf.PadDownstream && bf.Presorted {
+		return fmt.Errorf("--presorted can not be used when --pad-upstream and --pad-downstream differ")
+	}
+	return nil
+}
+
+// Verify chromosome alias combinations
+func (bf Bedfile) verifyChrAliasCombinations() error {
+	if bf.ChrAlias != "" && bf.ChrAliasSet != "" {
+		return fmt.Errorf("--chr-alias and --chr-alias-set can not be used together")
+	}
+	if bf.ChrNaming != "" && bf.ChrAlias == "" && bf.ChrAliasSet == "" {
+		return fmt.Errorf("--chr-naming must be used together with --chr-alias or --chr-alias-set")
+	}
+	return nil
+}
+
 // Verify first base input
 func (bf *Bedfile) verifyFirstBase() error {
 	if bf.FirstBase < 0 || bf.FirstBase > 1 {
@@ -99,6 +265,46 @@ func (bf *Bedfile) verifyFirstBase() error {
 	return nil
 }
 
+// Verify output combinations
+func (bf Bedfile) verifyOutputCombinations() error {
+	if bf.Tabix && !bf.Bgzip {
+		return fmt.Errorf("--tabix must be used together with --bgzip")
+	}
+	if bf.Tabix && bf.Output == "" {
+		return fmt.Errorf("--tabix must be used together with --output")
+	}
+	return nil
+}
+
+// Verify max memory input and convert it to bytes
+func (bf *Bedfile) verifyAndHandleMaxMemory() error {
+	if bf.MaxMemory == "" {
+		return nil
+	}
+	maxMemory, err := ParseMemorySize(bf.MaxMemory)
+	if err != nil {
+		return fmt.Errorf("--max-memory %v", err)
+	}
+	bf.maxMemory = maxMemory
+	return nil
+}
+
+// Convert memory size (e.g. 500M, 4G or 1024) to bytes
+func ParseMemorySize(size string) (int, error) {
+	units := map[string]int{"": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+	trimmed := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(size)), "B")
+	unit := strings.TrimLeft(trimmed, "0123456789")
+	multiplier, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("has unknown unit: %s", size)
+	}
+	number, err := strconv.Atoi(strings.TrimSuffix(trimmed, unit))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("must be a positive size: %s", size)
+	}
+	return number * multiplier, nil
+}
+
 // Create chr order map
 func (bf *Bedfile) handleCCSSorting() {
 	// Creating chromosome order map only if from custom chromosome
@@ -131,4 +337,13 @@ func (bf *Bedfile) cleanPaths() {
 	if bf.FastaIdx != "" {
 		bf.FastaIdx = filepath.Clean(bf.FastaIdx)
 	}
+	if bf.Fasta != "" {
+		bf.Fasta = filepath.Clean(bf.Fasta)
+	}
+	if bf.TmpDir != "" {
+		bf.TmpDir = filepath.Clean(bf.TmpDir)
+	}
+	if bf.ChrAlias != "" {
+		bf.ChrAlias = filepath.Clean(bf.ChrAlias)
+	}
 }
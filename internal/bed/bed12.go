@@ -0,0 +1,208 @@
+package bed
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BED12 column constants
+const (
+	scoreB12Idx       = 4
+	strandB12Idx      = 5
+	thickStartB12Idx  = 6
+	thickEndB12Idx    = 7
+	itemRgbB12Idx     = 8
+	blockCountB12Idx  = 9
+	blockSizesB12Idx  = 10
+	blockStartsB12Idx = 11
+	nrOfB12Cols       = 12
+)
+
+// Columns that are recalculated from the blocks and thick
+// region instead of being joined when merging BED12 lines
+var blockB12Idxs = []int{thickStartB12Idx, thickEndB12Idx, blockCountB12Idx, blockSizesB12Idx, blockStartsB12Idx}
+
+// Block (e.g. exon) of a BED12 line, in chromosome coordinates
+type block struct {
+	start int
+	stop  int
+}
+
+// Verify BED12 options
+//
+// Regions on different strands can not be merged into a valid BED12 line,
+// so the strand column is used if --strand-col is not set. The score and
+// itemRgb columns keep the value of the first line unless --col-op is set.
+// Must be run after the columns and column operations are converted to
+// zero-based indexing.
+func (bf *Bedfile) verifyAndHandleBed12() error {
+	if !bf.Bed12 {
+		return nil
+	}
+	if bf.StrandCol == 0 {
+		bf.StrandCol = strandB12Idx
+	}
+	for _, col := range blockB12Idxs {
+		if bf.StrandCol == col || bf.FeatCol == col {
+			return fmt.Errorf("--strand-col and --feat-col can not be set to the thick or block columns (7, 8, 10, 11 and 12) together with --bed12")
+		}
+		if _, ok := bf.colOps[col]; ok {
+			return fmt.Errorf("--col-op can not be used on the thick or block columns (7, 8, 10, 11 and 12) together with --bed12")
+		}
+	}
+	if bf.colOps == nil {
+		bf.colOps = map[int]string{}
+	}
+	for _, col := range []int{scoreB12Idx, itemRgbB12Idx} {
+		if _, ok := bf.colOps[col]; !ok && col != bf.StrandCol && col != bf.FeatCol {
+			bf.colOps[col] = "first"
+		}
+	}
+	return nil
+}
+
+// Verify that a line is a valid BED12 line
+func verifyBed12Line(l Line, lineNr int) error {
+	full := l.Full()
+	if len(full) < nrOfB12Cols {
+		return fmt.Errorf("expected at least %d columns on line %d got %d", nrOfB12Cols, lineNr, len(full))
+	}
+	for _, idx := range []int{thickStartB12Idx, thickEndB12Idx, blockCountB12Idx} {
+		if _, err := strconv.Atoi(full[idx]); err != nil {
+			return fmt.Errorf("non-int value in column %d on line %d: %s", idx+1, lineNr, full[idx])
+		}
+	}
+	blockCount, _ := strconv.Atoi(full[blockCountB12Idx])
+	sizes, err := splitInts(full[blockSizesB12Idx])
+	if err != nil {
+		return fmt.Errorf("non-int block size on line %d: %s", lineNr, full[blockSizesB12Idx])
+	}
+	starts, err := splitInts(full[blockStartsB12Idx])
+	if err != nil {
+		return fmt.Errorf("non-int block start on line %d: %s", lineNr, full[blockStartsB12Idx])
+	}
+	if blockCount < 1 || len(sizes) != blockCount || len(starts) != blockCount {
+		return fmt.Errorf("block count on line %d does not match the number of block sizes and starts: %d, %d and %d",
+			lineNr, blockCount, len(sizes), len(starts))
+	}
+	if starts[0] != 0 || starts[blockCount-1]+sizes[blockCount-1] != l.Stop-l.Start {
+		return fmt.Errorf("blocks on line %d do not span the region from start to stop", lineNr)
+	}
+	return nil
+}
+
+// Returns the blocks of a verified BED12 line in chromosome coordinates
+func blocksOf(l Line) []block {
+	full := l.Full()
+	sizes, _ := splitInts(full[blockSizesB12Idx])
+	starts, _ := splitInts(full[blockStartsB12Idx])
+	blocks := make([]block, len(starts))
+	for i := range starts {
+		blocks[i] = block{start: l.Start + starts[i], stop: l.Start + starts[i] + sizes[i]}
+	}
+	return blocks
+}
+
+// Returns the thick region of a verified BED12 line,
+// and false if the line has no thick region
+func thickOf(l Line) (block, bool) {
+	full := l.Full()
+	thickStart, _ := strconv.Atoi(full[thickStartB12Idx])
+	thickEnd, _ := strconv.Atoi(full[thickEndB12Idx])
+	return block{start: thickStart, stop: thickEnd}, thickStart < thickEnd
+}
+
+// Merge the blocks and thick region of l into the merged line
+//
+// The blocks are united, and the thick region spans
+// from the smallest to the largest thick region
+func mergeBed12Line(merged *Line, l Line) {
+	blocks := append(blocksOf(*merged), blocksOf(l)...)
+	thick, hasThick := thickOf(*merged)
+	if lThick, ok := thickOf(l); ok {
+		if hasThick {
+			thick = block{start: min(thick.start, lThick.start), stop: max(thick.stop, lThick.stop)}
+		} else {
+			thick, hasThick = lThick, true
+		}
+	}
+	if !hasThick {
+		thick = block{start: merged.Start, stop: merged.Start}
+	}
+	setBed12Columns(merged, blocks, thick)
+}
+
+// Adjust the blocks and thick region of a padded line, where
+// start and stop are the coordinates of the line before padding
+//
+// The outermost blocks are extended to the new start and stop, and
+// blocks and thick regions outside of the padded region are trimmed
+func padBed12Line(padded *Line, start, stop int) {
+	unpadded := Line{Start: start, Stop: stop, Opt: padded.Opt}
+	thick, _ := thickOf(unpadded)
+	setBed12Columns(padded, blocksOf(unpadded), thick)
+}
+
+// Set the thick and block columns of a line from blocks and a thick
+// region in chromosome coordinates
+//
+// The blocks are sorted and united, trimmed to the region of the line
+// and the outermost blocks are extended to the start and stop of the line
+func setBed12Columns(l *Line, blocks []block, thick block) {
+	slices.SortFunc(blocks, func(a, b block) int {
+		return cmp.Or(cmp.Compare(a.start, b.start), cmp.Compare(a.stop, b.stop))
+	})
+	var united []block
+	for _, b := range blocks {
+		b.start = max(b.start, l.Start)
+		b.stop = min(b.stop, l.Stop)
+		if b.start >= b.stop {
+			continue
+		}
+		if len(united) > 0 && b.start <= united[len(united)-1].stop {
+			united[len(united)-1].stop = max(united[len(united)-1].stop, b.stop)
+			continue
+		}
+		united = append(united, b)
+	}
+	if len(united) == 0 {
+		united = []block{{start: l.Start, stop: l.Stop}}
+	}
+	united[0].start = l.Start
+	united[len(united)-1].stop = l.Stop
+
+	// Keep the trailing commas of the block lists if they were used
+	full := l.Full()
+	suffix := ""
+	if strings.HasSuffix(full[blockSizesB12Idx], ",") {
+		suffix = ","
+	}
+	sizes := make([]string, len(united))
+	starts := make([]string, len(united))
+	for i, b := range united {
+		sizes[i] = strconv.Itoa(b.stop - b.start)
+		starts[i] = strconv.Itoa(b.start - l.Start)
+	}
+	full[thickStartB12Idx] = strconv.Itoa(min(max(thick.start, l.Start), l.Stop))
+	full[thickEndB12Idx] = strconv.Itoa(min(max(thick.stop, l.Start), l.Stop))
+	full[blockCountB12Idx] = strconv.Itoa(len(united))
+	full[blockSizesB12Idx] = strings.Join(sizes, ",") + suffix
+	full[blockStartsB12Idx] = strings.Join(starts, ",") + suffix
+	l.setOptCols(full[firstOptIdx:])
+}
+
+// Split a comma separated list of integers, ignoring a trailing comma
+func splitInts(list string) ([]int, error) {
+	var ints []int
+	for _, s := range strings.Split(strings.TrimSuffix(list, ","), ",") {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
@@ -0,0 +1,292 @@
+package bed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// Create BED12 test line from a tab separated string
+func bed12Line(t *testing.T, lineText string) Line {
+	t.Helper()
+	bf := Bedfile{Bed12: true, StrandCol: strandB12Idx}
+	if err := bf.readBed(strings.NewReader(lineText)); err != nil {
+		t.Fatal(err)
+	}
+	return bf.Lines[0]
+}
+
+func TestVerifyAndHandleBed12(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		bf         Bedfile
+		expectedBf Bedfile
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing:    "not bed12",
+			bf:         Bedfile{},
+			expectedBf: Bedfile{},
+		},
+		{
+			testing: "default strand column and column operations",
+			bf:      Bedfile{Bed12: true},
+			expectedBf: Bedfile{
+				Bed12: true, StrandCol: strandB12Idx,
+				colOps: map[int]string{scoreB12Idx: "first", itemRgbB12Idx: "first"},
+			},
+		},
+		{
+			testing: "column operation on score",
+			bf:      Bedfile{Bed12: true, StrandCol: strandB12Idx, colOps: map[int]string{scoreB12Idx: "max"}},
+			expectedBf: Bedfile{
+				Bed12: true, StrandCol: strandB12Idx,
+				colOps: map[int]string{scoreB12Idx: "max", itemRgbB12Idx: "first"},
+			},
+		},
+		{
+			testing: "feature column on itemRgb",
+			bf:      Bedfile{Bed12: true, FeatCol: itemRgbB12Idx},
+			expectedBf: Bedfile{
+				Bed12: true, StrandCol: strandB12Idx, FeatCol: itemRgbB12Idx,
+				colOps: map[int]string{scoreB12Idx: "first"},
+			},
+		},
+		{
+			testing:    "column operation on block column",
+			bf:         Bedfile{Bed12: true, colOps: map[int]string{blockSizesB12Idx: "first"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "feature column on thick column",
+			bf:         Bedfile{Bed12: true, FeatCol: thickStartB12Idx},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bf.verifyAndHandleBed12()
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedBf, tc.bf); diff != nil {
+					t.Error("expected VS received bed", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyBed12Line(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		lineText   string
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing:  "valid line",
+			lineText: "1\t100\t200\tA\t0\t+\t100\t200\t0\t2\t10,20\t0,80",
+		},
+		{
+			testing:  "valid line with trailing commas",
+			lineText: "1\t100\t200\tA\t0\t+\t100\t200\t0\t2\t10,20,\t0,80,",
+		},
+		{
+			testing:    "too few columns",
+			lineText:   "1\t100\t200\tA\t0\t+\t100\t200\t0",
+			shouldFail: true,
+		},
+		{
+			testing:    "non-int thick start",
+			lineText:   "1\t100\t200\tA\t0\t+\tx\t200\t0\t2\t10,20\t0,80",
+			shouldFail: true,
+		},
+		{
+			testing:    "non-int block size",
+			lineText:   "1\t100\t200\tA\t0\t+\t100\t200\t0\t2\t10,x\t0,80",
+			shouldFail: true,
+		},
+		{
+			testing:    "block count does not match",
+			lineText:   "1\t100\t200\tA\t0\t+\t100\t200\t0\t3\t10,20\t0,80",
+			shouldFail: true,
+		},
+		{
+			testing:    "first block does not start at start",
+			lineText:   "1\t100\t200\tA\t0\t+\t100\t200\t0\t2\t10,20\t5,80",
+			shouldFail: true,
+		},
+		{
+			testing:    "last block does not end at stop",
+			lineText:   "1\t100\t200\tA\t0\t+\t100\t200\t0\t2\t10,20\t0,70",
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			bf := Bedfile{Bed12: true}
+			err := bf.readBed(strings.NewReader(tc.lineText))
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestMergeBed12(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bf            Bedfile
+		lines         []string
+		expectedLines []string
+	}
+	testCases := []testCase{
+		{
+			testing: "blocks are united",
+			bf:      Bedfile{Bed12: true},
+			lines: []string{
+				"1\t1000\t2000\tTX1\t0\t+\t1100\t1900\t255,0,0\t3\t100,200,100,\t0,400,900,",
+				"1\t1500\t2500\tTX2\t0\t+\t1500\t2400\t0,0,255\t2\t200,100,\t0,900,",
+			},
+			expectedLines: []string{
+				"1\t1000\t2500\tTX1,TX2\t0\t+\t1100\t2400\t255,0,0\t4\t100,300,100,100,\t0,400,900,1400,",
+			},
+		},
+		{
+			testing: "touching blocks are joined",
+			bf:      Bedfile{Bed12: true},
+			lines: []string{
+				"1\t0\t100\tA\t0\t+\t0\t0\t0\t2\t10,10\t0,90",
+				"1\t10\t50\tB\t0\t+\t0\t0\t0\t1\t40\t0",
+			},
+			expectedLines: []string{
+				"1\t0\t100\tA,B\t0\t+\t0\t0\t0\t2\t50,10\t0,90",
+			},
+		},
+		{
+			testing: "thick region from the line with a thick region",
+			bf:      Bedfile{Bed12: true},
+			lines: []string{
+				"1\t0\t100\tA\t0\t+\t0\t0\t0\t1\t100\t0",
+				"1\t50\t200\tB\t0\t+\t60\t150\t0\t1\t150\t0",
+			},
+			expectedLines: []string{
+				"1\t0\t200\tA,B\t0\t+\t60\t150\t0\t1\t200\t0",
+			},
+		},
+		{
+			testing: "lines on different strands are not merged",
+			bf:      Bedfile{Bed12: true},
+			lines: []string{
+				"1\t0\t100\tA\t0\t+\t0\t0\t0\t1\t100\t0",
+				"1\t50\t200\tB\t0\t-\t60\t150\t0\t1\t150\t0",
+			},
+			expectedLines: []string{
+				"1\t0\t100\tA\t0\t+\t0\t0\t0\t1\t100\t0",
+				"1\t50\t200\tB\t0\t-\t60\t150\t0\t1\t150\t0",
+			},
+		},
+		{
+			testing: "padding before merging",
+			bf: Bedfile{
+				Bed12: true, Padding: 10, PaddingType: SafePT,
+				chrLengthMap: testChrLengthMap,
+			},
+			lines: []string{
+				"1\t5\t40\tA\t0\t+\t5\t40\t0\t2\t10,10\t0,25",
+				"1\t60\t90\tB\t0\t+\t70\t80\t0\t1\t30\t0",
+			},
+			expectedLines: []string{
+				"1\t0\t100\tA,B\t0\t+\t5\t80\t0\t2\t15,70\t0,30",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			if err := tc.bf.verifyAndHandleBed12(); err != nil {
+				t.Fatal(err)
+			}
+			for _, lineText := range tc.lines {
+				tc.bf.Lines = append(tc.bf.Lines, bed12Line(t, lineText))
+			}
+			if err := tc.bf.MergeAndPadLines(); err != nil {
+				t.Fatal(err)
+			}
+			var receivedLines []string
+			for _, l := range tc.bf.Lines {
+				receivedLines = append(receivedLines, l.text())
+			}
+			if diff := deep.Equal(tc.expectedLines, receivedLines); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
+
+func TestPadBed12(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing      string
+		bf           Bedfile
+		lineText     string
+		expectedLine string
+	}
+	testCases := []testCase{
+		{
+			testing:      "outermost blocks are extended",
+			bf:           Bedfile{Bed12: true, Padding: 10, chrLengthMap: testChrLengthMap},
+			lineText:     "1\t20\t80\tA\t0\t+\t30\t70\t0\t2\t10,10,\t0,50,",
+			expectedLine: "1\t10\t90\tA\t0\t+\t30\t70\t0\t2\t20,20,\t0,60,",
+		},
+		{
+			testing:      "padding is limited by the chromosome",
+			bf:           Bedfile{Bed12: true, Padding: 30, chrLengthMap: testChrLengthMap},
+			lineText:     "1\t20\t80\tA\t0\t+\t30\t70\t0\t2\t10,10\t0,50",
+			expectedLine: "1\t0\t100\tA\t0\t+\t30\t70\t0\t2\t30,30\t0,70",
+		},
+		{
+			testing:      "negative padding trims blocks and thick region",
+			bf:           Bedfile{Bed12: true, Padding: -15, chrLengthMap: testChrLengthMap},
+			lineText:     "1\t20\t80\tA\t0\t+\t20\t80\t0\t3\t10,10,10\t0,25,50",
+			expectedLine: "1\t35\t65\tA\t0\t+\t35\t65\t0\t1\t30\t0",
+		},
+		{
+			testing:      "negative padding removes outer blocks",
+			bf:           Bedfile{Bed12: true, Padding: -5, chrLengthMap: testChrLengthMap},
+			lineText:     "1\t20\t80\tA\t0\t+\t20\t80\t0\t4\t4,10,10,4\t0,20,30,56",
+			expectedLine: "1\t25\t75\tA\t0\t+\t25\t75\t0\t1\t50\t0",
+		},
+		{
+			testing:      "stranded padding",
+			bf:           Bedfile{Bed12: true, StrandCol: strandB12Idx, PadUpstream: 10, chrLengthMap: testChrLengthMap},
+			lineText:     "1\t20\t80\tA\t0\t-\t30\t70\t0\t2\t10,10\t0,50",
+			expectedLine: "1\t20\t90\tA\t0\t-\t30\t70\t0\t2\t10,20\t0,50",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			padded, _, err := tc.bf.padLine(bed12Line(t, tc.lineText))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tc.expectedLine, padded.text()); diff != nil {
+				t.Error("expected VS received line", diff)
+			}
+		})
+	}
+}
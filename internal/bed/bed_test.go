@@ -1,6 +1,7 @@
 package bed
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -191,6 +192,91 @@ func TestVerifyFastaIdxCombinations(t *testing.T) {
 	}
 }
 
+func TestVerifyPaddingCombinations(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		bed        Bedfile
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "padding only",
+			bed: Bedfile{
+				Padding: 2,
+			},
+		},
+		{
+			testing: "upstream and downstream padding with strand col",
+			bed: Bedfile{
+				StrandCol:     3,
+				PadUpstream:   10,
+				PadDownstream: 2,
+			},
+		},
+		{
+			testing: "left and right padding",
+			bed: Bedfile{
+				PadLeft:  10,
+				PadRight: 2,
+			},
+		},
+		{
+			testing: "presorted with equal upstream and downstream padding",
+			bed: Bedfile{
+				StrandCol:     3,
+				Presorted:     true,
+				PadUpstream:   10,
+				PadDownstream: 10,
+			},
+		},
+		{
+			testing: "padding together with left padding",
+			bed: Bedfile{
+				Padding: 2,
+				PadLeft: 10,
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "upstream padding together with right padding",
+			bed: Bedfile{
+				StrandCol:   3,
+				PadUpstream: 10,
+				PadRight:    2,
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "downstream padding without strand col",
+			bed: Bedfile{
+				PadDownstream: 2,
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "presorted with different upstream and downstream padding",
+			bed: Bedfile{
+				StrandCol:     3,
+				Presorted:     true,
+				PadUpstream:   10,
+				PadDownstream: 2,
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bed.verifyPaddingCombinations()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
 func TestVerifyFirstBase(t *testing.T) {
 	t.Parallel()
 	type testCase struct {
@@ -385,15 +471,13 @@ func TestChrOrderMap(t *testing.T) {
 func deepCopyLines(lines []Line) []Line {
 	var copiedLines []Line
 	for _, l := range lines {
-		fullLineCopy := make([]string, len(l.Full))
-		_ = copy(fullLineCopy, l.Full)
 		copiedLine := Line{
 			Chr:    l.Chr,
 			Start:  l.Start,
 			Stop:   l.Stop,
 			Strand: l.Strand,
 			Feat:   l.Feat,
-			Full:   fullLineCopy,
+			Opt:    bytes.Clone(l.Opt),
 		}
 		copiedLines = append(copiedLines, copiedLine)
 	}
@@ -402,15 +486,13 @@ func deepCopyLines(lines []Line) []Line {
 
 // To make deep copy line
 func deepCopyLine(line Line) Line {
-	fullLineCopy := make([]string, len(line.Full))
-	_ = copy(fullLineCopy, line.Full)
 	copiedLine := Line{
 		Chr:    line.Chr,
 		Start:  line.Start,
 		Stop:   line.Stop,
 		Strand: line.Strand,
 		Feat:   line.Feat,
-		Full:   fullLineCopy,
+		Opt:    bytes.Clone(line.Opt),
 	}
 	return copiedLine
 }
@@ -0,0 +1,375 @@
+package bed
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Input formats
+var BedpeIF = "bedpe" // BEDPE file, each line contains a pair of regions (anchors)
+
+// BEDPE column constants
+const (
+	chr1PIdx    = 0
+	chr2PIdx    = 3
+	stop2PIdx   = 5
+	strand1PIdx = 8
+	strand2PIdx = 9
+	minNrPCols  = 6
+)
+
+// Strands of BEDPE files, which only use the . + - notation
+var bedpeStrandPattern = regexp.MustCompile(`^(\.|\+|-)$`)
+
+// Pair of regions from a BEDPE line
+//
+// Each anchor holds the chr, start, stop and strand of one of the
+// regions, and the columns chr, start and stop in Full. The chr of
+// unknown anchors is . and the start and stop is -1.
+type Pair struct {
+	Anchors  [2]Line
+	Full     []string
+	Comments []string
+}
+
+// Verify BEDPE options
+func (bf Bedfile) verifyBedpe() error {
+	if bf.InputFormat != BedpeIF {
+		return nil
+	}
+	if bf.Presorted || bf.MaxMemory != "" {
+		return fmt.Errorf("--presorted and --max-memory can not be used together with --input-format=%s", BedpeIF)
+	}
+	if bf.StrandCol != 0 || bf.FeatCol != 0 || len(bf.ColOp) > 0 || bf.Bed12 {
+		return fmt.Errorf("--strand-col, --feat-col, --col-op and --bed12 can not be used together with --input-format=%s, the strands are read from column 9 and 10", BedpeIF)
+	}
+	if bf.Tabix {
+		return fmt.Errorf("--tabix can not be used together with --input-format=%s", BedpeIF)
+	}
+	return nil
+}
+
+// Verify that the input is not BEDPE, for the functions that
+// only handle single regions
+func (bf Bedfile) verifyNotBedpe() error {
+	if bf.InputFormat == BedpeIF {
+		return fmt.Errorf("--input-format=%s is only supported by the default command", BedpeIF)
+	}
+	return nil
+}
+
+// Opening and reading the BEDPE files and optional fasta index file
+func (bf *Bedfile) ReadPairs() error {
+	if err := bf.readChrAliasFile(); err != nil {
+		return err
+	}
+	for _, input := range bf.Inputs {
+		bedpeFile, err := openInput(input)
+		if err != nil {
+			return err
+		}
+		defer bedpeFile.Close()
+		if err := bf.readBedpe(bedpeFile); err != nil {
+			return fmt.Errorf("can't read bedpe file %s: %q", input, err)
+		}
+	}
+	if err := bf.readFastaIdxFile(); err != nil {
+		return err
+	}
+	bf.chrAliasWarnings()
+	return nil
+}
+
+// Reading a BEDPE file from a reader instead of the input paths,
+// decompressing it if it is gzip or BGZF compressed
+//
+// The name is only used in messages
+func (bf *Bedfile) ReadPairsFrom(name string, r io.Reader) error {
+	if err := bf.readChrAliasFile(); err != nil {
+		return err
+	}
+	reader, gzipReader, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("can't read bedpe file %s: %q", name, err)
+	}
+	if gzipReader != nil {
+		defer gzipReader.Close()
+	}
+	if err := bf.readBedpe(reader); err != nil {
+		return fmt.Errorf("can't read bedpe file %s: %q", name, err)
+	}
+	bf.chrAliasWarnings()
+	return nil
+}
+
+// Reading the BEDPE file
+//
+// Header and comment lines are handled in the same way as in bed files
+func (bf *Bedfile) readBedpe(file io.Reader) error {
+	var expectedNrOfCols int
+	if len(bf.Pairs) != 0 {
+		expectedNrOfCols = len(bf.Pairs[0].Full)
+	}
+	firstFile := expectedNrOfCols == 0
+
+	regionsRead := false
+	lineNr := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNr++
+		lineText := scanner.Text()
+
+		// Handle headers and comments
+		if headerPattern.MatchString(lineText) {
+			if regionsRead && commentPattern.MatchString(lineText) {
+				bf.addComment(lineText)
+			} else {
+				bf.addHeader(lineText, firstFile)
+			}
+			continue
+		}
+		regionsRead = true
+
+		p := Pair{Full: strings.Split(lineText, "\t")}
+		if expectedNrOfCols == 0 {
+			expectedNrOfCols = len(p.Full)
+			if expectedNrOfCols < minNrPCols {
+				return fmt.Errorf("less than %d columns on line %d: %s", minNrPCols, lineNr, lineText)
+			}
+		}
+		if len(p.Full) != expectedNrOfCols {
+			return fmt.Errorf("expected %d columns on line %d got %d: %s",
+				expectedNrOfCols, lineNr, len(p.Full), lineText)
+		}
+
+		// Fill anchors
+		for i, idx := range []int{chr1PIdx, chr2PIdx} {
+			a := Line{Chr: p.Full[idx]}
+			if a.Chr != "." {
+				a.Chr = bf.resolveChr(a.Chr)
+			}
+			var err error
+			a.Start, err = strconv.Atoi(p.Full[idx+1])
+			if err != nil {
+				return fmt.Errorf("non-int start%d position on line %d: %s", i+1, lineNr, p.Full[idx+1])
+			}
+			a.Stop, err = strconv.Atoi(p.Full[idx+2])
+			if err != nil {
+				return fmt.Errorf("non-int stop%d position on line %d: %s", i+1, lineNr, p.Full[idx+2])
+			}
+			if a.Start > a.Stop {
+				return fmt.Errorf("start%d is greater than stop%d on line %d: %d > %d", i+1, i+1, lineNr, a.Start, a.Stop)
+			}
+			if strandIdx := strand1PIdx + i; strandIdx < len(p.Full) {
+				a.Strand = p.Full[strandIdx]
+				if !bedpeStrandPattern.MatchString(a.Strand) {
+					return fmt.Errorf("unexpected strand%d format on line %d: %s", i+1, lineNr, a.Strand)
+				}
+			}
+			p.Anchors[i] = a
+		}
+		p.updateFull()
+		p.Comments = bf.takeComments()
+		bf.Pairs = append(bf.Pairs, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Returns true if the anchor is unknown
+func isUnknownAnchor(a Line) bool {
+	return a.Chr == "." || a.Start < 0
+}
+
+// Copy the chr, start and stop of the anchors into the columns of the pair
+func (p *Pair) updateFull() {
+	for i, idx := range []int{chr1PIdx, chr2PIdx} {
+		copy(p.Full[idx:idx+3], p.Anchors[i].Full())
+	}
+}
+
+// Pad both anchors of the pairs, unknown anchors are not padded
+func (bf *Bedfile) PadPairs() error {
+	var chrNotInLengthMap []string
+	for i := range bf.Pairs {
+		var err error
+		chrNotInLengthMap, err = bf.padPair(&bf.Pairs[i], chrNotInLengthMap)
+		if err != nil {
+			return err
+		}
+	}
+	bf.paddingWarnings(chrNotInLengthMap)
+	return nil
+}
+
+// Pad both anchors of a pair
+func (bf Bedfile) padPair(p *Pair, chrNotInLengthMap []string) ([]string, error) {
+	for i, a := range p.Anchors {
+		if isUnknownAnchor(a) {
+			continue
+		}
+		var err error
+		p.Anchors[i], chrNotInLengthMap, err = bf.padAccordingToPaddingType(a, chrNotInLengthMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.updateFull()
+	return chrNotInLengthMap, nil
+}
+
+// Pad and merge pairs where both anchors overlap
+//
+// The anchors of two pairs overlap if they are on the same chromosome
+// and strand, and are overlapping or touching after --overlap has been
+// added, in the same way as when merging lines. The pairs are sorted,
+// and each pair is merged into the first earlier pair it overlaps.
+func (bf *Bedfile) MergeAndPadPairs() error {
+	if bf.HasPadding() {
+		if err := bf.PadPairs(); err != nil {
+			return err
+		}
+	}
+	bf.Pairs = bf.clusterPairs(bf.mergePairInto)
+	return nil
+}
+
+// Remove pairs where both anchors overlap an earlier pair,
+// see .MergeAndPadPairs() for when anchors overlap
+func (bf *Bedfile) DeduplicatePairs() {
+	bf.Pairs = bf.clusterPairs(func(*Pair, Pair) {})
+}
+
+// Group pairs where both anchors overlap, pairs are passed on to
+// merge together with the first earlier pair they overlap
+func (bf Bedfile) clusterPairs(merge func(cluster *Pair, p Pair)) []Pair {
+	pairs := slices.Clone(bf.Pairs)
+	slices.SortStableFunc(pairs, func(a, b Pair) int {
+		return cmp.Or(
+			cmp.Compare(a.Anchors[0].Chr, b.Anchors[0].Chr),
+			cmp.Compare(a.Anchors[1].Chr, b.Anchors[1].Chr),
+			cmp.Compare(a.Anchors[0].Strand, b.Anchors[0].Strand),
+			cmp.Compare(a.Anchors[1].Strand, b.Anchors[1].Strand),
+			cmp.Compare(a.Anchors[0].Start, b.Anchors[0].Start),
+			cmp.Compare(a.Anchors[0].Stop, b.Anchors[0].Stop),
+		)
+	})
+	var clusters []Pair
+	// Clusters that pairs can still be merged into
+	var open []int
+	for _, p := range pairs {
+		var stillOpen []int
+		merged := false
+		for _, c := range open {
+			if !bf.anchorsCanOverlap(clusters[c].Anchors[0], p.Anchors[0]) {
+				continue
+			}
+			stillOpen = append(stillOpen, c)
+			if !merged && bf.anchorsOverlap(clusters[c].Anchors[1], p.Anchors[1]) &&
+				bf.anchorsOverlap(clusters[c].Anchors[0], p.Anchors[0]) &&
+				clusters[c].Anchors[0].Strand == p.Anchors[0].Strand &&
+				clusters[c].Anchors[1].Strand == p.Anchors[1].Strand {
+				merge(&clusters[c], p)
+				clusters[c].Comments = append(clusters[c].Comments, p.Comments...)
+				merged = true
+			}
+		}
+		if !merged {
+			clusters = append(clusters, Pair{Anchors: p.Anchors, Full: slices.Clone(p.Full), Comments: p.Comments})
+			stillOpen = append(stillOpen, len(clusters)-1)
+		}
+		open = stillOpen
+	}
+	return clusters
+}
+
+// Returns true if later anchors, sorted by chromosome
+// and start, can still overlap the first anchor
+func (bf Bedfile) anchorsCanOverlap(a, later Line) bool {
+	return a.Chr == later.Chr && a.Stop+bf.Overlap >= later.Start-1
+}
+
+// Returns true if the anchors overlap, unknown anchors
+// only overlap other unknown anchors
+func (bf Bedfile) anchorsOverlap(a, b Line) bool {
+	if isUnknownAnchor(a) || isUnknownAnchor(b) {
+		return isUnknownAnchor(a) && isUnknownAnchor(b)
+	}
+	return a.Chr == b.Chr &&
+		a.Stop+bf.Overlap >= b.Start-1 &&
+		b.Stop+bf.Overlap >= a.Start-1
+}
+
+// Merge pair into an already merged pair by extending the
+// anchors and joining the information in the optional columns
+func (bf Bedfile) mergePairInto(merged *Pair, p Pair) {
+	for i, a := range p.Anchors {
+		if isUnknownAnchor(a) {
+			continue
+		}
+		merged.Anchors[i].Start = min(merged.Anchors[i].Start, a.Start)
+		merged.Anchors[i].Stop = max(merged.Anchors[i].Stop, a.Stop)
+	}
+	merged.updateFull()
+	for idx := stop2PIdx + 1; idx < len(p.Full); idx++ {
+		if idx == strand1PIdx || idx == strand2PIdx {
+			continue
+		}
+		if !stringInSlice(strings.Split(merged.Full[idx], ","), p.Full[idx]) {
+			merged.Full[idx] = fmt.Sprintf("%s,%s", merged.Full[idx], p.Full[idx])
+		}
+	}
+}
+
+// Sort pairs by the first and then the second anchor
+// according to the sorting type
+func (bf *Bedfile) SortPairs() error {
+	compare, err := bf.lineCompareFunc()
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(bf.Pairs, func(a, b Pair) int {
+		return cmp.Or(
+			compare(a.Anchors[0], b.Anchors[0]),
+			compare(a.Anchors[1], b.Anchors[1]),
+		)
+	})
+	return nil
+}
+
+// Writing BEDPE file or standard output
+func (bf *Bedfile) WritePairs() error {
+	return bf.openOutput(bf.writePairs)
+}
+
+// Writing BEDPE file to a writer instead of the output path
+func (bf *Bedfile) WritePairsTo(writer io.Writer) error {
+	return bf.writePairs(writer)
+}
+
+// Writing pairs to writer destination
+func (bf *Bedfile) writePairs(writer io.Writer) error {
+	lw := bf.newLineWriter(writer)
+	for _, h := range bf.Header {
+		if err := lw.writeHeader(h); err != nil {
+			return err
+		}
+	}
+	for _, p := range bf.Pairs {
+		if err := lw.writeText(strings.Join(p.Full, "\t"), p.Comments); err != nil {
+			return err
+		}
+	}
+	if err := lw.writeComments(bf.comments); err != nil {
+		return err
+	}
+	return lw.close()
+}
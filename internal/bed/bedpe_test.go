@@ -0,0 +1,381 @@
+package bed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// Read pairs from lines, failing the test if they can not be read
+func readTestPairs(t *testing.T, bf *Bedfile, lines []string) {
+	t.Helper()
+	if err := bf.readBedpe(strings.NewReader(strings.Join(lines, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Return the pairs as lines
+func pairsToLines(pairs []Pair) []string {
+	var lines []string
+	for _, p := range pairs {
+		lines = append(lines, strings.Join(p.Full, "\t"))
+	}
+	return lines
+}
+
+func TestReadBedpe(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing        string
+		content        string
+		expectedHeader []string
+		expectedPairs  []Pair
+		shouldFail     bool
+	}
+	testCases := []testCase{
+		{
+			testing:        "pairs with strands and header",
+			content:        "# pairs\n1\t10\t20\t2\t30\t40\tP1\t5\t+\t-",
+			expectedHeader: []string{"# pairs"},
+			expectedPairs: []Pair{
+				{
+					Anchors: [2]Line{
+						{Chr: "1", Start: 10, Stop: 20, Strand: "+"},
+						{Chr: "2", Start: 30, Stop: 40, Strand: "-"},
+					},
+					Full: []string{"1", "10", "20", "2", "30", "40", "P1", "5", "+", "-"},
+				},
+			},
+		},
+		{
+			testing: "pair with unknown anchor and without strands",
+			content: "1\t10\t20\t.\t-1\t-1",
+			expectedPairs: []Pair{
+				{
+					Anchors: [2]Line{
+						{Chr: "1", Start: 10, Stop: 20},
+						{Chr: ".", Start: -1, Stop: -1},
+					},
+					Full: []string{"1", "10", "20", ".", "-1", "-1"},
+				},
+			},
+		},
+		{
+			testing:    "too few columns",
+			content:    "1\t10\t20\t2\t30",
+			shouldFail: true,
+		},
+		{
+			testing:    "different number of columns",
+			content:    "1\t10\t20\t2\t30\t40\n1\t10\t20\t2\t30\t40\tP2",
+			shouldFail: true,
+		},
+		{
+			testing:    "non-int start of the second anchor",
+			content:    "1\t10\t20\t2\tx\t40",
+			shouldFail: true,
+		},
+		{
+			testing:    "start greater than stop",
+			content:    "1\t30\t20\t2\t30\t40",
+			shouldFail: true,
+		},
+		{
+			testing:    "unexpected strand",
+			content:    "1\t10\t20\t2\t30\t40\tP1\t5\t+\t1",
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			bf := Bedfile{InputFormat: BedpeIF}
+			err := bf.readBedpe(strings.NewReader(tc.content))
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedHeader, bf.Header); diff != nil {
+					t.Error("expected VS received header", diff)
+				}
+				if diff := deep.Equal(tc.expectedPairs, bf.Pairs); diff != nil {
+					t.Error("expected VS received pairs", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyBedpe(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		bf         Bedfile
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "bedpe with stranded padding",
+			bf:      Bedfile{InputFormat: BedpeIF, PadUpstream: 10, PaddingType: ForcePT, SortType: LexST},
+		},
+		{
+			testing:    "bedpe with strand column",
+			bf:         Bedfile{InputFormat: BedpeIF, StrandCol: 9, SortType: LexST},
+			shouldFail: true,
+		},
+		{
+			testing:    "bedpe with presorted",
+			bf:         Bedfile{InputFormat: BedpeIF, Presorted: true, SortType: LexST},
+			shouldFail: true,
+		},
+		{
+			testing:    "bedpe with bed12",
+			bf:         Bedfile{InputFormat: BedpeIF, Bed12: true, SortType: LexST},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			tc.bf.Threads = 1 // default of --threads
+			err := tc.bf.VerifyAndHandle()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestPadPairs(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bf            Bedfile
+		lines         []string
+		expectedLines []string
+		shouldFail    bool
+	}
+	testCases := []testCase{
+		{
+			testing: "both anchors are padded and clamped",
+			bf:      Bedfile{Padding: 10, PaddingType: SafePT, chrLengthMap: testChrLengthMap},
+			lines:   []string{"1\t5\t20\t2\t100\t195\tP1"},
+			expectedLines: []string{
+				"1\t0\t30\t2\t90\t200\tP1",
+			},
+		},
+		{
+			testing: "anchors are padded according to their own strand",
+			bf:      Bedfile{PadUpstream: 10, PaddingType: SafePT, chrLengthMap: testChrLengthMap},
+			lines:   []string{"1\t20\t30\t2\t20\t30\tP1\t0\t+\t-"},
+			expectedLines: []string{
+				"1\t10\t30\t2\t20\t40\tP1\t0\t+\t-",
+			},
+		},
+		{
+			testing: "unknown anchors are not padded",
+			bf:      Bedfile{Padding: 10, PaddingType: SafePT, chrLengthMap: testChrLengthMap},
+			lines:   []string{"1\t20\t30\t.\t-1\t-1"},
+			expectedLines: []string{
+				"1\t10\t40\t.\t-1\t-1",
+			},
+		},
+		{
+			testing:    "chromosome not in the fasta index",
+			bf:         Bedfile{Padding: 10, PaddingType: SafePT, chrLengthMap: testChrLengthMap},
+			lines:      []string{"1\t20\t30\t5\t20\t30"},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			readTestPairs(t, &tc.bf, tc.lines)
+			err := tc.bf.PadPairs()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedLines, pairsToLines(tc.bf.Pairs)); diff != nil {
+					t.Error("expected VS received lines", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeAndPadPairs(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bf            Bedfile
+		lines         []string
+		expectedLines []string
+	}
+	testCases := []testCase{
+		{
+			testing: "pairs where both anchors overlap are merged",
+			bf:      Bedfile{},
+			lines: []string{
+				"1\t10\t20\t2\t30\t40\tP1\t5",
+				"1\t15\t25\t2\t35\t45\tP2\t5",
+				"1\t26\t30\t2\t46\t50\tP3\t7",
+			},
+			expectedLines: []string{
+				"1\t10\t30\t2\t30\t50\tP1,P2,P3\t5,7",
+			},
+		},
+		{
+			testing: "pairs where only one anchor overlaps are not merged",
+			bf:      Bedfile{},
+			lines: []string{
+				"1\t10\t20\t2\t30\t40\tP1",
+				"1\t15\t25\t2\t60\t70\tP2",
+				"1\t15\t25\t3\t30\t40\tP3",
+			},
+			expectedLines: []string{
+				"1\t10\t20\t2\t30\t40\tP1",
+				"1\t15\t25\t2\t60\t70\tP2",
+				"1\t15\t25\t3\t30\t40\tP3",
+			},
+		},
+		{
+			testing: "overlap tolerance",
+			bf:      Bedfile{Overlap: 5},
+			lines: []string{
+				"1\t10\t20\t2\t30\t40\tP1",
+				"1\t25\t30\t2\t45\t50\tP2",
+			},
+			expectedLines: []string{
+				"1\t10\t30\t2\t30\t50\tP1,P2",
+			},
+		},
+		{
+			testing: "pairs on different strands are not merged",
+			bf:      Bedfile{},
+			lines: []string{
+				"1\t10\t20\t2\t30\t40\tP1\t0\t+\t-",
+				"1\t15\t25\t2\t35\t45\tP2\t0\t+\t+",
+			},
+			expectedLines: []string{
+				"1\t15\t25\t2\t35\t45\tP2\t0\t+\t+",
+				"1\t10\t20\t2\t30\t40\tP1\t0\t+\t-",
+			},
+		},
+		{
+			testing: "padding before merging",
+			bf:      Bedfile{Padding: 5, PaddingType: SafePT, chrLengthMap: testChrLengthMap},
+			lines: []string{
+				"1\t10\t20\t2\t30\t40\tP1",
+				"1\t30\t40\t2\t50\t60\tP2",
+			},
+			expectedLines: []string{
+				"1\t5\t45\t2\t25\t65\tP1,P2",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			readTestPairs(t, &tc.bf, tc.lines)
+			if err := tc.bf.MergeAndPadPairs(); err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tc.expectedLines, pairsToLines(tc.bf.Pairs)); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
+
+func TestDeduplicatePairs(t *testing.T) {
+	t.Parallel()
+	bf := Bedfile{Overlap: -1}
+	readTestPairs(t, &bf, []string{
+		"1\t10\t20\t2\t30\t40\tP1",
+		"1\t12\t18\t2\t32\t38\tP2",
+		"1\t21\t30\t2\t30\t40\tP3",
+		"1\t10\t20\t2\t30\t40\tP4",
+	})
+	bf.DeduplicatePairs()
+	expectedLines := []string{
+		"1\t10\t20\t2\t30\t40\tP1",
+		"1\t21\t30\t2\t30\t40\tP3",
+	}
+	if diff := deep.Equal(expectedLines, pairsToLines(bf.Pairs)); diff != nil {
+		t.Error("expected VS received lines", diff)
+	}
+}
+
+func TestSortPairs(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bf            Bedfile
+		lines         []string
+		expectedLines []string
+	}
+	lines := []string{
+		"10\t5\t10\t1\t5\t10",
+		"2\t5\t10\t2\t5\t10",
+		"2\t5\t10\t10\t5\t10",
+		"2\t5\t10\t1\t5\t10",
+		"1\t5\t10\tX\t5\t10",
+	}
+	testCases := []testCase{
+		{
+			testing: "lexicographic sorting",
+			bf:      Bedfile{SortType: LexST},
+			lines:   lines,
+			expectedLines: []string{
+				"1\t5\t10\tX\t5\t10",
+				"10\t5\t10\t1\t5\t10",
+				"2\t5\t10\t1\t5\t10",
+				"2\t5\t10\t10\t5\t10",
+				"2\t5\t10\t2\t5\t10",
+			},
+		},
+		{
+			testing: "natural sorting",
+			bf:      Bedfile{SortType: NatST},
+			lines:   lines,
+			expectedLines: []string{
+				"1\t5\t10\tX\t5\t10",
+				"2\t5\t10\t1\t5\t10",
+				"2\t5\t10\t2\t5\t10",
+				"2\t5\t10\t10\t5\t10",
+				"10\t5\t10\t1\t5\t10",
+			},
+		},
+		{
+			testing: "custom chromosome sorting",
+			bf:      Bedfile{SortType: CcsST, chrOrderMap: map[string]int{"X": 1, "10": 2, "2": 3, "1": 4}},
+			lines:   lines,
+			expectedLines: []string{
+				"10\t5\t10\t1\t5\t10",
+				"2\t5\t10\t10\t5\t10",
+				"2\t5\t10\t2\t5\t10",
+				"2\t5\t10\t1\t5\t10",
+				"1\t5\t10\tX\t5\t10",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			readTestPairs(t, &tc.bf, tc.lines)
+			if err := tc.bf.SortPairs(); err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tc.expectedLines, pairsToLines(tc.bf.Pairs)); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
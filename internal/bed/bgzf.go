@@ -0,0 +1,126 @@
+package bed
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// BGZF constants
+const (
+	// Maximum amount of uncompressed data in a block (same as bgzip)
+	bgzfBlockSize = 0xff00
+	// Size of the gzip header including the BGZF extra field
+	bgzfHeaderSize = 18
+	// Size of the gzip footer (CRC32 and ISIZE)
+	bgzfFooterSize = 8
+)
+
+// Empty block marking the end of a BGZF file
+var bgzfEOF = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0xff, 0x06, 0x00, 0x42, 0x43, 0x02, 0x00,
+	0x1b, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00,
+}
+
+// Writer compressing the content into BGZF blocks
+//
+// BGZF files are gzip files made up of several members (blocks) that
+// can be decompressed independently. Positions in the uncompressed
+// content are given as virtual offsets, which are the offset of the
+// block in the compressed file shifted 16 bits to the left combined
+// with the offset within the uncompressed block.
+type bgzfWriter struct {
+	writer      io.Writer
+	block       []byte
+	blockOffset uint64
+	compressed  bytes.Buffer
+	flater      *flate.Writer
+}
+
+// Create new BGZF writer
+func newBgzfWriter(writer io.Writer) *bgzfWriter {
+	// flate.NewWriter only fails for invalid compression levels
+	flater, _ := flate.NewWriter(nil, flate.DefaultCompression)
+	return &bgzfWriter{
+		writer: writer,
+		block:  make([]byte, 0, bgzfBlockSize),
+		flater: flater,
+	}
+}
+
+// Write content to blocks, blocks are written when they are full
+func (bw *bgzfWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(bgzfBlockSize-len(bw.block), len(p))
+		bw.block = append(bw.block, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(bw.block) == bgzfBlockSize {
+			if err := bw.flushBlock(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Virtual offset of the next byte to be written
+func (bw *bgzfWriter) offset() uint64 {
+	return bw.blockOffset<<16 | uint64(len(bw.block))
+}
+
+// Compress and write the current block
+func (bw *bgzfWriter) flushBlock() error {
+	if len(bw.block) == 0 {
+		return nil
+	}
+	bw.compressed.Reset()
+	bw.flater.Reset(&bw.compressed)
+	if _, err := bw.flater.Write(bw.block); err != nil {
+		return err
+	}
+	if err := bw.flater.Close(); err != nil {
+		return err
+	}
+	blockSize := bgzfHeaderSize + bw.compressed.Len() + bgzfFooterSize
+
+	header := []byte{
+		0x1f, 0x8b, // gzip magic
+		0x08,                   // compression method (deflate)
+		0x04,                   // flags (extra field)
+		0x00, 0x00, 0x00, 0x00, // modification time
+		0x00,       // extra flags
+		0xff,       // operating system (unknown)
+		0x06, 0x00, // length of extra field
+		0x42, 0x43, // BGZF subfield identifier (BC)
+		0x02, 0x00, // length of subfield
+		0x00, 0x00, // total block size - 1
+	}
+	binary.LittleEndian.PutUint16(header[16:], uint16(blockSize-1))
+	footer := make([]byte, bgzfFooterSize)
+	binary.LittleEndian.PutUint32(footer[0:], crc32.ChecksumIEEE(bw.block))
+	binary.LittleEndian.PutUint32(footer[4:], uint32(len(bw.block)))
+
+	for _, part := range [][]byte{header, bw.compressed.Bytes(), footer} {
+		if _, err := bw.writer.Write(part); err != nil {
+			return err
+		}
+	}
+	bw.blockOffset += uint64(blockSize)
+	bw.block = bw.block[:0]
+	return nil
+}
+
+// Write the remaining content and the end of file marker
+func (bw *bgzfWriter) Close() error {
+	if err := bw.flushBlock(); err != nil {
+		return err
+	}
+	_, err := bw.writer.Write(bgzfEOF)
+	return err
+}
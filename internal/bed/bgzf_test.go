@@ -0,0 +1,102 @@
+package bed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBgzfWriter(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing        string
+		content        string
+		expectedBlocks int
+	}
+	testCases := []testCase{
+		{
+			testing:        "empty",
+			content:        "",
+			expectedBlocks: 1,
+		},
+		{
+			testing:        "single block",
+			content:        "1\t10\t100\n2\t20\t200\n",
+			expectedBlocks: 2,
+		},
+		{
+			testing:        "several blocks",
+			content:        strings.Repeat("1\t10\t100\n", 20000),
+			expectedBlocks: 4,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var compressed bytes.Buffer
+			bw := newBgzfWriter(&compressed)
+			if _, err := bw.Write([]byte(tc.content)); err != nil {
+				t.Fatal(err)
+			}
+			if err := bw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			// Verify that the blocks are valid gzip members
+			reader, err := gzip.NewReader(bytes.NewReader(compressed.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tc.content {
+				t.Errorf("expected %d bytes got %d bytes", len(tc.content), len(content))
+			}
+			// Verify the block sizes given in the BGZF extra field
+			blocks := 0
+			data := compressed.Bytes()
+			for len(data) > 0 {
+				blockSize := int(binary.LittleEndian.Uint16(data[16:18])) + 1
+				if blockSize > len(data) {
+					t.Fatalf("block size %d is larger than the remaining %d bytes", blockSize, len(data))
+				}
+				data = data[blockSize:]
+				blocks++
+			}
+			if blocks != tc.expectedBlocks {
+				t.Errorf("expected %d blocks got %d", tc.expectedBlocks, blocks)
+			}
+			if !bytes.HasSuffix(compressed.Bytes(), bgzfEOF) {
+				t.Error("missing BGZF end of file marker")
+			}
+		})
+	}
+}
+
+func TestBgzfOffset(t *testing.T) {
+	t.Parallel()
+	var compressed bytes.Buffer
+	bw := newBgzfWriter(&compressed)
+	if bw.offset() != 0 {
+		t.Errorf("expected offset 0 got %d", bw.offset())
+	}
+	if _, err := bw.Write([]byte("1\t10\t100\n")); err != nil {
+		t.Fatal(err)
+	}
+	if bw.offset() != 9 {
+		t.Errorf("expected offset 9 got %d", bw.offset())
+	}
+	// Filling the block moves the offset to the start of the next block
+	if _, err := bw.Write(make([]byte, bgzfBlockSize-9)); err != nil {
+		t.Fatal(err)
+	}
+	expectedOffset := uint64(compressed.Len()) << 16
+	if bw.offset() != expectedOffset {
+		t.Errorf("expected offset %d got %d", expectedOffset, bw.offset())
+	}
+}
@@ -0,0 +1,161 @@
+package bed
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Built-in chromosome alias sets
+var GRCh37CA = "grch37" // GRCh37/hg19 primary assembly
+var GRCh38CA = "grch38" // GRCh38/hg38 primary assembly
+
+//go:embed chralias/*.txt
+var builtinChrAliases embed.FS
+
+// Reading the chromosome alias file or built-in alias set if one is selected
+//
+// The aliases are only read once, so that they can be
+// shared with other bed files read together with this one
+func (bf *Bedfile) readChrAliasFile() error {
+	if bf.chrAliases != nil || (bf.ChrAlias == "" && bf.ChrAliasSet == "") {
+		return nil
+	}
+	var file io.ReadCloser
+	var err error
+	name := bf.ChrAlias
+	if bf.ChrAliasSet != "" {
+		name = bf.ChrAliasSet
+		file, err = builtinChrAliases.Open("chralias/" + bf.ChrAliasSet + ".txt")
+	} else {
+		file, err = openInput(bf.ChrAlias)
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	bf.chrAliases, err = readChrAliases(file, bf.ChrNaming)
+	if err != nil {
+		return fmt.Errorf("can't read chromosome alias file %s: %q", name, err)
+	}
+	return nil
+}
+
+// Reading a chromosome alias file from a reader instead of the
+// chromosome alias path or built-in alias set
+//
+// The name is used as the chromosome alias file in messages
+func (bf *Bedfile) ReadChrAliasFrom(name string, r io.Reader) error {
+	bf.ChrAlias = name
+	reader, gzipReader, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("can't read chromosome alias file %s: %q", name, err)
+	}
+	if gzipReader != nil {
+		defer gzipReader.Close()
+	}
+	bf.chrAliases, err = readChrAliases(reader, bf.ChrNaming)
+	if err != nil {
+		return fmt.Errorf("can't read chromosome alias file %s: %q", name, err)
+	}
+	return nil
+}
+
+// Reading a chromosome alias file in the format of the UCSC chromAlias.txt files
+//
+// Each line contains the tab separated names of one chromosome, and the
+// optional header line (starting with #) names the naming styles of the
+// columns. Returns a map from every name to the name in the column given
+// by naming, or in the first column if naming is empty.
+func readChrAliases(file io.Reader, naming string) (map[string]string, error) {
+	aliases := map[string]string{}
+	namingIdx := 0
+	lineNr := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNr++
+		lineText := scanner.Text()
+		if lineText == "" {
+			continue
+		}
+		cols := strings.Split(lineText, "\t")
+
+		// Find the column of the naming style in the header
+		if strings.HasPrefix(lineText, "#") {
+			if lineNr != 1 {
+				continue
+			}
+			cols[0] = strings.TrimSpace(strings.TrimPrefix(cols[0], "#"))
+			if naming != "" {
+				namingIdx = slices.Index(cols, naming)
+				if namingIdx == -1 {
+					return nil, fmt.Errorf("naming style %s is not in the header, must be one of %v", naming, cols)
+				}
+			}
+			continue
+		}
+		if naming != "" && lineNr == 1 {
+			return nil, fmt.Errorf("the file has no header naming the naming styles, can't select naming style %s", naming)
+		}
+
+		// Chromosomes without a name in the chosen naming style are skipped
+		if namingIdx >= len(cols) || cols[namingIdx] == "" {
+			continue
+		}
+		for _, alias := range cols {
+			if alias == "" {
+				continue
+			}
+			if name, ok := aliases[alias]; ok && name != cols[namingIdx] {
+				return nil, fmt.Errorf("alias %s on line %d is already an alias for %s", alias, lineNr, name)
+			}
+			aliases[alias] = cols[namingIdx]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(aliases) == 0 {
+		return nil, fmt.Errorf("no aliases found")
+	}
+	return aliases, nil
+}
+
+// Convert chromosome name to the chosen naming style
+//
+// Names without an alias are kept and recorded so that they can be reported
+func (bf *Bedfile) resolveChr(chr string) string {
+	if bf.chrAliases == nil {
+		return chr
+	}
+	if name, ok := bf.chrAliases[chr]; ok {
+		return name
+	}
+	if bf.chrNoAlias == nil {
+		bf.chrNoAlias = map[string]bool{}
+	}
+	bf.chrNoAlias[chr] = true
+	return chr
+}
+
+// Warn about chromosomes without an alias
+func (bf Bedfile) chrAliasWarnings() {
+	if len(bf.chrNoAlias) == 0 {
+		return
+	}
+	var chrs []string
+	for chr := range bf.chrNoAlias {
+		chrs = append(chrs, chr)
+	}
+	name := bf.ChrAlias
+	if bf.ChrAliasSet != "" {
+		name = bf.ChrAliasSet
+	}
+	bf.warn("chromosomes %v have no alias in %s, their names were not changed",
+		sortAndDeduplicateListOfStrings(chrs), name)
+	// Only warn once about each chromosome
+	clear(bf.chrNoAlias)
+}
@@ -0,0 +1,200 @@
+package bed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestReadChrAliases(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		content         string
+		naming          string
+		expectedAliases map[string]string
+		shouldFail      bool
+	}
+	testCases := []testCase{
+		{
+			testing: "header, first column",
+			content: "# ucsc\tensembl\trefseq\n" +
+				"chr1\t1\tNC_000001.11\n" +
+				"chrM\tMT\tNC_012920.1\n",
+			expectedAliases: map[string]string{
+				"chr1": "chr1", "1": "chr1", "NC_000001.11": "chr1",
+				"chrM": "chrM", "MT": "chrM", "NC_012920.1": "chrM",
+			},
+		},
+		{
+			testing: "header, selected naming and missing names",
+			content: "# ucsc\tensembl\trefseq\n" +
+				"chr1\t1\tNC_000001.11\n" +
+				"chrUn_gl000220\t\t\n" +
+				"chrM\tMT\t\n",
+			naming: "ensembl",
+			expectedAliases: map[string]string{
+				"chr1": "1", "1": "1", "NC_000001.11": "1",
+				"chrM": "MT", "MT": "MT",
+			},
+		},
+		{
+			testing: "no header",
+			content: "chr1\t1\n" +
+				"chrM\tMT\n",
+			expectedAliases: map[string]string{
+				"chr1": "chr1", "1": "chr1",
+				"chrM": "chrM", "MT": "chrM",
+			},
+		},
+		{
+			testing: "no header, selected naming",
+			content: "chr1\t1\n" +
+				"chrM\tMT\n",
+			naming:     "ensembl",
+			shouldFail: true,
+		},
+		{
+			testing: "unknown naming",
+			content: "# ucsc\tensembl\n" +
+				"chr1\t1\n",
+			naming:     "refseq",
+			shouldFail: true,
+		},
+		{
+			testing: "same alias for two chromosomes",
+			content: "# ucsc\tensembl\n" +
+				"chr1\t1\n" +
+				"chr2\t1\n",
+			shouldFail: true,
+		},
+		{
+			testing:    "empty file",
+			content:    "",
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			aliases, err := readChrAliases(strings.NewReader(tc.content), tc.naming)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedAliases, aliases); diff != nil {
+					t.Error("expected VS received aliases", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestBuiltinChrAliases(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		bed             Bedfile
+		chrs            []string
+		expectedChrs    []string
+		expectedNoAlias map[string]bool
+	}
+	testCases := []testCase{
+		{
+			testing:      "grch37 to ucsc",
+			bed:          Bedfile{ChrAliasSet: GRCh37CA},
+			chrs:         []string{"1", "chrX", "NC_000024.9"},
+			expectedChrs: []string{"chr1", "chrX", "chrY"},
+		},
+		{
+			testing:         "grch37 chrM and MT are different sequences",
+			bed:             Bedfile{ChrAliasSet: GRCh37CA, ChrNaming: "ensembl"},
+			chrs:            []string{"chrM", "MT", "NC_012920.1"},
+			expectedChrs:    []string{"chrM", "MT", "MT"},
+			expectedNoAlias: map[string]bool{"chrM": true},
+		},
+		{
+			testing:      "grch38 to ensembl",
+			bed:          Bedfile{ChrAliasSet: GRCh38CA, ChrNaming: "ensembl"},
+			chrs:         []string{"chr1", "chrM", "X", "NC_000024.10"},
+			expectedChrs: []string{"1", "MT", "X", "Y"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			if err := tc.bed.readChrAliasFile(); err != nil {
+				t.Fatal(err)
+			}
+			var chrs []string
+			for _, chr := range tc.chrs {
+				chrs = append(chrs, tc.bed.resolveChr(chr))
+			}
+			if diff := deep.Equal(tc.expectedChrs, chrs); diff != nil {
+				t.Error("expected VS received chromosomes", diff)
+			}
+			if diff := deep.Equal(tc.expectedNoAlias, tc.bed.chrNoAlias); diff != nil {
+				t.Error("expected VS received chromosomes without an alias", diff)
+			}
+		})
+	}
+}
+
+func TestResolveChr(t *testing.T) {
+	t.Parallel()
+	bf := Bedfile{
+		chrAliases: map[string]string{"1": "chr1", "chr1": "chr1"},
+	}
+	var chrs []string
+	for _, chr := range []string{"1", "chr1", "chrUn", "2", "chrUn"} {
+		chrs = append(chrs, bf.resolveChr(chr))
+	}
+	if diff := deep.Equal([]string{"chr1", "chr1", "chrUn", "2", "chrUn"}, chrs); diff != nil {
+		t.Error("expected VS received chromosomes", diff)
+	}
+	if diff := deep.Equal(map[string]bool{"chrUn": true, "2": true}, bf.chrNoAlias); diff != nil {
+		t.Error("expected VS received chromosomes without alias", diff)
+	}
+}
+
+func TestVerifyChrAliasCombinations(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		bed        Bedfile
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "alias file with naming",
+			bed:     Bedfile{ChrAlias: "/some/path/chromAlias.txt", ChrNaming: "ucsc"},
+		},
+		{
+			testing: "alias set",
+			bed:     Bedfile{ChrAliasSet: GRCh38CA},
+		},
+		{
+			testing:    "alias file and alias set",
+			bed:        Bedfile{ChrAlias: "/some/path/chromAlias.txt", ChrAliasSet: GRCh38CA},
+			shouldFail: true,
+		},
+		{
+			testing:    "naming without aliases",
+			bed:        Bedfile{ChrNaming: "ucsc"},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bed.verifyChrAliasCombinations()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
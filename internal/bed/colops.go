@@ -0,0 +1,227 @@
+package bed
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Aggregation operations that can be used on the optional
+// columns when merging regions (see --col-op)
+var colOpFuncs = map[string]func(values []string) (string, error){
+	"sum":            sumColOp,
+	"mean":           meanColOp,
+	"min":            minColOp,
+	"max":            maxColOp,
+	"median":         medianColOp,
+	"count":          countColOp,
+	"count_distinct": countDistinctColOp,
+	"collapse":       collapseColOp,
+	"distinct":       distinctColOp,
+	"first":          firstColOp,
+	"last":           lastColOp,
+}
+
+// Values of the columns with an aggregation operation
+// for the lines merged into a region, by column index
+type colValues map[int][]string
+
+// Verify the column operations given as <column>:<operation>
+// and convert the columns to zero-based indexing
+func (bf *Bedfile) verifyAndHandleColOps() error {
+	if len(bf.ColOp) == 0 {
+		return nil
+	}
+	bf.colOps = map[int]string{}
+	for _, colOp := range bf.ColOp {
+		colString, op, found := strings.Cut(colOp, ":")
+		if !found {
+			return fmt.Errorf("--col-op must be given as <column>:<operation>: %s", colOp)
+		}
+		col, err := strconv.Atoi(colString)
+		if err != nil {
+			return fmt.Errorf("--col-op column is not an integer: %s", colOp)
+		}
+		if col <= stopIdx+1 {
+			return fmt.Errorf("--col-op can only be used on optional columns (column 4 and above): %s", colOp)
+		}
+		if _, ok := colOpFuncs[op]; !ok {
+			return fmt.Errorf("--col-op has unknown operation %s, must be one of %v", op, colOpNames())
+		}
+		col--
+		if col == bf.StrandCol || col == bf.FeatCol {
+			return fmt.Errorf("--col-op can not be used on --strand-col or --feat-col: %s", colOp)
+		}
+		if _, ok := bf.colOps[col]; ok {
+			return fmt.Errorf("--col-op is given more than once for column %d", col+1)
+		}
+		bf.colOps[col] = op
+	}
+	return nil
+}
+
+// Sorted names of the column operations
+func colOpNames() []string {
+	var names []string
+	for name := range colOpFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Collect the values of the columns with an aggregation
+// operation for the first line of a merged region
+func (bf *Bedfile) newColValues(l Line) colValues {
+	if len(bf.colOps) == 0 {
+		return nil
+	}
+	values := colValues{}
+	values.add(bf.colOps, l)
+	return values
+}
+
+// Add the values of the columns with an aggregation operation
+func (values colValues) add(colOps map[int]string, l Line) {
+	for col := range colOps {
+		if value, ok := l.col(col); ok {
+			values[col] = append(values[col], value)
+		}
+	}
+}
+
+// Replace the columns with an aggregation operation in the
+// merged line with the result of the operation
+func (bf *Bedfile) applyColOps(merged *Line, values colValues) error {
+	if values == nil {
+		return nil
+	}
+	cols := merged.optCols()
+	// Columns are handled in order, so that the error names
+	// the same column every time
+	for _, col := range slices.Sorted(maps.Keys(bf.colOps)) {
+		op := bf.colOps[col]
+		if col >= firstOptIdx+len(cols) {
+			return fmt.Errorf("--col-op column %d is larger than the number of columns (%d) in line: %s",
+				col+1, firstOptIdx+len(cols), merged.text())
+		}
+		result, err := colOpFuncs[op](values[col])
+		if err != nil {
+			return fmt.Errorf("can't use --col-op %d:%s on the region %s:%d-%d: %v",
+				col+1, op, merged.Chr, merged.Start, merged.Stop, err)
+		}
+		cols[col-firstOptIdx] = result
+	}
+	merged.setOptCols(cols)
+	return nil
+}
+
+// Convert values to numbers
+func parseNumbers(values []string) ([]float64, error) {
+	numbers := make([]float64, len(values))
+	for i, v := range values {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value is not a number: %q", v)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// Format number without trailing zeros
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func sumColOp(values []string) (string, error) {
+	numbers, err := parseNumbers(values)
+	if err != nil {
+		return "", err
+	}
+	var sum float64
+	for _, n := range numbers {
+		sum += n
+	}
+	return formatNumber(sum), nil
+}
+
+func meanColOp(values []string) (string, error) {
+	numbers, err := parseNumbers(values)
+	if err != nil {
+		return "", err
+	}
+	var sum float64
+	for _, n := range numbers {
+		sum += n
+	}
+	return formatNumber(sum / float64(len(numbers))), nil
+}
+
+func minColOp(values []string) (string, error) {
+	numbers, err := parseNumbers(values)
+	if err != nil {
+		return "", err
+	}
+	return formatNumber(slices.Min(numbers)), nil
+}
+
+func maxColOp(values []string) (string, error) {
+	numbers, err := parseNumbers(values)
+	if err != nil {
+		return "", err
+	}
+	return formatNumber(slices.Max(numbers)), nil
+}
+
+// The mean of the two middle values is used for an even number of values
+func medianColOp(values []string) (string, error) {
+	numbers, err := parseNumbers(values)
+	if err != nil {
+		return "", err
+	}
+	slices.Sort(numbers)
+	middle := len(numbers) / 2
+	if len(numbers)%2 == 0 {
+		return formatNumber((numbers[middle-1] + numbers[middle]) / 2), nil
+	}
+	return formatNumber(numbers[middle]), nil
+}
+
+func countColOp(values []string) (string, error) {
+	return strconv.Itoa(len(values)), nil
+}
+
+func countDistinctColOp(values []string) (string, error) {
+	return strconv.Itoa(len(distinctValues(values))), nil
+}
+
+func collapseColOp(values []string) (string, error) {
+	return strings.Join(values, ","), nil
+}
+
+func distinctColOp(values []string) (string, error) {
+	return strings.Join(distinctValues(values), ","), nil
+}
+
+func firstColOp(values []string) (string, error) {
+	return values[0], nil
+}
+
+func lastColOp(values []string) (string, error) {
+	return values[len(values)-1], nil
+}
+
+// Distinct values in the order they are first seen
+func distinctValues(values []string) []string {
+	var distinct []string
+	for _, v := range values {
+		if !stringInSlice(distinct, v) {
+			distinct = append(distinct, v)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,163 @@
+package bed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestVerifyAndHandleColOps(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing     string
+		bed         Bedfile
+		expectedBed Bedfile
+		shouldFail  bool
+	}
+	testCases := []testCase{
+		{
+			testing: "no column operations",
+			bed: Bedfile{
+				StrandCol: 3,
+			},
+			expectedBed: Bedfile{
+				StrandCol: 3,
+			},
+		},
+		{
+			testing: "correct column operations",
+			bed: Bedfile{
+				ColOp: []string{"5:sum", "6:count_distinct"},
+			},
+			expectedBed: Bedfile{
+				ColOp:  []string{"5:sum", "6:count_distinct"},
+				colOps: map[int]string{4: "sum", 5: "count_distinct"},
+			},
+		},
+		{
+			testing: "missing operation",
+			bed: Bedfile{
+				ColOp: []string{"5"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "column not an integer",
+			bed: Bedfile{
+				ColOp: []string{"score:sum"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "column not an optional column",
+			bed: Bedfile{
+				ColOp: []string{"3:sum"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "unknown operation",
+			bed: Bedfile{
+				ColOp: []string{"5:average"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "operation on strand column",
+			bed: Bedfile{
+				StrandCol: 3,
+				ColOp:     []string{"4:first"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "operation on feat column",
+			bed: Bedfile{
+				FeatCol: 4,
+				ColOp:   []string{"5:first"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "column given twice",
+			bed: Bedfile{
+				ColOp: []string{"5:sum", "5:mean"},
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bed.verifyAndHandleColOps()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedBed, tc.bed); diff != nil {
+					t.Error("expected VS received bed", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestColOpFuncs(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing        string
+		op             string
+		values         []string
+		expectedResult string
+		shouldFail     bool
+	}
+	testCases := []testCase{
+		{testing: "sum", op: "sum", values: []string{"1", "2.5", "3"}, expectedResult: "6.5"},
+		{testing: "sum of non-numeric values", op: "sum", values: []string{"1", "A"}, shouldFail: true},
+		{testing: "mean", op: "mean", values: []string{"1", "2", "6"}, expectedResult: "3"},
+		{testing: "min", op: "min", values: []string{"3", "-1", "2"}, expectedResult: "-1"},
+		{testing: "max", op: "max", values: []string{"3", "-1", "2"}, expectedResult: "3"},
+		{testing: "max of non-numeric values", op: "max", values: []string{"A"}, shouldFail: true},
+		{testing: "median of odd number of values", op: "median", values: []string{"5", "1", "3"}, expectedResult: "3"},
+		{testing: "median of even number of values", op: "median", values: []string{"4", "1", "3", "10"}, expectedResult: "3.5"},
+		{testing: "count", op: "count", values: []string{"A", "A", "B"}, expectedResult: "3"},
+		{testing: "count distinct", op: "count_distinct", values: []string{"A", "A", "B"}, expectedResult: "2"},
+		{testing: "collapse", op: "collapse", values: []string{"A", "A", "B"}, expectedResult: "A,A,B"},
+		{testing: "distinct", op: "distinct", values: []string{"B", "A", "B"}, expectedResult: "B,A"},
+		{testing: "first", op: "first", values: []string{"B", "A", "C"}, expectedResult: "B"},
+		{testing: "last", op: "last", values: []string{"B", "A", "C"}, expectedResult: "C"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			result, err := colOpFuncs[tc.op](tc.values)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail && tc.expectedResult != result {
+				t.Errorf("expected result %q, but got %q", tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestApplyColOpsErrorNamesFirstColumn(t *testing.T) {
+	t.Parallel()
+	bf := Bedfile{colOps: map[int]string{3: "sum", 4: "sum", 5: "sum", 6: "sum"}}
+	line := Line{Chr: "1", Start: 1, Stop: 8, Opt: []byte("A\tB\tC\tD")}
+	expectedErr := "can't use --col-op 4:sum on the region 1:1-8: "
+	// Map iteration order is random, so the error is checked several times
+	for range 20 {
+		merged := line
+		err := bf.applyColOps(&merged, bf.newColValues(line))
+		if err == nil {
+			t.Fatal("expected error, but got nil")
+		}
+		if !strings.HasPrefix(err.Error(), expectedErr) {
+			t.Fatalf("expected error starting with %q, but got %q", expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package bed
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Replace the lines with the regions of the chromosomes in the
+// fasta index file that are not covered by any line
+//
+// The regions start at --first-base. If --first-base is 0 the
+// regions are half-open like in the bed standard, if it is 1 the
+// regions are closed, so that the complement of 1-10 starts at 11.
+// Lines on chromosomes not in the fasta index file are ignored.
+func (bf *Bedfile) Complement() error {
+	if bf.chrLengthMap == nil {
+		return fmt.Errorf("can't find the complement without a fasta index file")
+	}
+	var chrNotInLengthMap []string
+	regions := map[string][]Line{}
+	for _, l := range bf.Lines {
+		if _, ok := bf.chrLengthMap[l.Chr]; !ok {
+			chrNotInLengthMap = append(chrNotInLengthMap, l.Chr)
+			continue
+		}
+		regions[l.Chr] = append(regions[l.Chr], l)
+	}
+	if len(chrNotInLengthMap) > 0 {
+		bf.warn("chromosomes %v not in %s, regions on these chromosomes were ignored",
+			sortAndDeduplicateListOfStrings(chrNotInLengthMap), bf.chrLengthsName())
+	}
+
+	chrs := slices.SortedFunc(maps.Keys(bf.chrLengthMap), func(a, b string) int {
+		return stringMapCompare(a, b, bf.chrOrderMap)
+	})
+	var complement []Line
+	for _, chr := range chrs {
+		lines := regions[chr]
+		slices.SortFunc(lines, func(a, b Line) int {
+			return cmp.Compare(a.Start, b.Start)
+		})
+		// The first position that is not covered by the previous lines
+		uncovered := bf.FirstBase
+		for _, l := range lines {
+			if l.Start > uncovered {
+				complement = append(complement, newRegion(chr, uncovered, l.Start-bf.FirstBase))
+			}
+			uncovered = max(uncovered, l.Stop+bf.FirstBase)
+		}
+		if chrLength := bf.chrLengthMap[chr]; uncovered < chrLength+bf.FirstBase {
+			complement = append(complement, newRegion(chr, uncovered, chrLength))
+		}
+	}
+	// The headers of the input do not describe the complement,
+	// but the track line of --header-policy=track does
+	bf.Header = nil
+	if bf.HeaderPolicy == TrackHP {
+		bf.Header = []string{bf.trackLine()}
+	}
+	bf.Lines = complement
+	return nil
+}
+
+// Create new line containing only chromosome, start and stop
+func newRegion(chr string, start, stop int) Line {
+	return Line{Chr: chr, Start: start, Stop: stop}
+}
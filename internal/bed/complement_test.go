@@ -0,0 +1,172 @@
+package bed
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+var testComplementChrOrderMap = map[string]int{
+	"1": 1,
+	"2": 2,
+	"3": 3,
+	"4": 4,
+}
+
+var testComplementLines = []Line{
+	{
+		Chr: "2", Start: 0, Stop: 50,
+		Opt: []byte("+"),
+	},
+	{
+		Chr: "1", Start: 40, Stop: 60,
+		Opt: []byte("A"),
+	},
+	{
+		Chr: "1", Start: 10, Stop: 20,
+		Opt: []byte("B"),
+	},
+	{
+		Chr: "1", Start: 15, Stop: 30,
+		Opt: []byte("C"),
+	},
+	{
+		Chr: "4", Start: 350, Stop: 400,
+	},
+	{
+		Chr: "5", Start: 10, Stop: 20,
+	},
+}
+
+func TestComplement(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing        string
+		bf             Bedfile
+		expectedLines  []Line
+		expectedHeader []string
+		shouldFail     bool
+	}
+	testCases := []testCase{
+		{
+			testing: "complement",
+			bf: Bedfile{
+				Header:       []string{"browser position 1:1-100"},
+				Lines:        deepCopyLines(testComplementLines),
+				chrOrderMap:  testComplementChrOrderMap,
+				chrLengthMap: testChrLengthMap,
+			},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 0, Stop: 10,
+				},
+				{
+					Chr: "1", Start: 30, Stop: 40,
+				},
+				{
+					Chr: "1", Start: 60, Stop: 100,
+				},
+				{
+					Chr: "2", Start: 50, Stop: 200,
+				},
+				{
+					Chr: "3", Start: 0, Stop: 300,
+				},
+				{
+					Chr: "4", Start: 0, Stop: 350,
+				},
+			},
+		},
+		{
+			testing: "complement with first base 1",
+			bf: Bedfile{
+				FirstBase:    1,
+				Lines:        deepCopyLines(testComplementLines),
+				chrOrderMap:  testComplementChrOrderMap,
+				chrLengthMap: testChrLengthMap,
+			},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 1, Stop: 9,
+				},
+				{
+					Chr: "1", Start: 31, Stop: 39,
+				},
+				{
+					Chr: "1", Start: 61, Stop: 100,
+				},
+				{
+					Chr: "2", Start: 51, Stop: 200,
+				},
+				{
+					Chr: "3", Start: 1, Stop: 300,
+				},
+				{
+					Chr: "4", Start: 1, Stop: 349,
+				},
+			},
+		},
+		{
+			testing: "touching regions with first base 1",
+			bf: Bedfile{
+				FirstBase: 1,
+				Lines: []Line{
+					{
+						Chr: "1", Start: 1, Stop: 10,
+					},
+					{
+						Chr: "1", Start: 11, Stop: 100,
+					},
+				},
+				chrOrderMap:  map[string]int{"1": 1},
+				chrLengthMap: map[string]int{"1": 100},
+			},
+		},
+		{
+			testing: "track line is kept with header policy track",
+			bf: Bedfile{
+				HeaderPolicy: TrackHP,
+				TrackLine:    "track name=x",
+				Header:       []string{"track name=x", "#moved to the top"},
+				Lines: []Line{
+					{
+						Chr: "1", Start: 10, Stop: 100,
+					},
+				},
+				chrOrderMap:  map[string]int{"1": 1},
+				chrLengthMap: map[string]int{"1": 100},
+			},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 0, Stop: 10,
+				},
+			},
+			expectedHeader: []string{"track name=x"},
+		},
+		{
+			testing: "without fasta index",
+			bf: Bedfile{
+				Lines: deepCopyLines(testComplementLines),
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bf.Complement()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedLines, tc.bf.Lines); diff != nil {
+					t.Error("expected VS received lines", diff)
+				}
+				if diff := deep.Equal(tc.expectedHeader, tc.bf.Header); diff != nil {
+					t.Error("expected VS received header", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package bed
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+)
+
+// Magic bytes at the start of gzip and BGZF files
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Input file that is decompressed if needed
+type inputFile struct {
+	io.Reader
+	file *os.File
+	gzip *gzip.Reader
+}
+
+// Close the decompressor and the underlying file
+func (f *inputFile) Close() error {
+	var gzipErr error
+	if f.gzip != nil {
+		gzipErr = f.gzip.Close()
+	}
+	return errors.Join(gzipErr, f.file.Close())
+}
+
+// Opening a file and decompressing it if it is gzip or BGZF compressed
+//
+// The compression is detected from the magic bytes, not the extension
+func openInput(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, gzipReader, err := decompress(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &inputFile{Reader: reader, file: file, gzip: gzipReader}, nil
+}
+
+// Returns a reader that decompresses the content if it starts with
+// the gzip magic bytes. BGZF files are gzip files with several members,
+// which are read as one stream. The gzip reader is nil if the content
+// is not compressed.
+func decompress(r io.Reader) (io.Reader, *gzip.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		return buffered, nil, nil
+	}
+	gzipReader, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gzipReader, gzipReader, nil
+}
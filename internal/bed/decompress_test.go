@@ -0,0 +1,78 @@
+package bed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+// Compress each part as a separate gzip member, like BGZF does
+func gzipMembers(t *testing.T, parts ...string) []byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	for _, part := range parts {
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write([]byte(part)); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return compressed.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		content         []byte
+		expectedContent string
+		shouldFail      bool
+	}
+	testCases := []testCase{
+		{
+			testing:         "plain text",
+			content:         []byte("1\t10\t100\n2\t20\t200\n"),
+			expectedContent: "1\t10\t100\n2\t20\t200\n",
+		},
+		{
+			testing:         "empty file",
+			content:         []byte{},
+			expectedContent: "",
+		},
+		{
+			testing:         "gzip",
+			content:         gzipMembers(t, "1\t10\t100\n2\t20\t200\n"),
+			expectedContent: "1\t10\t100\n2\t20\t200\n",
+		},
+		{
+			testing:         "several gzip members (BGZF)",
+			content:         gzipMembers(t, "1\t10\t100\n", "2\t20\t200\n", ""),
+			expectedContent: "1\t10\t100\n2\t20\t200\n",
+		},
+		{
+			testing:    "broken gzip header",
+			content:    []byte{0x1f, 0x8b, 0x00},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			reader, _, err := decompress(bytes.NewReader(tc.content))
+			if err == nil {
+				var content []byte
+				content, err = io.ReadAll(reader)
+				if !tc.shouldFail && string(content) != tc.expectedContent {
+					t.Errorf("expected %q got %q", tc.expectedContent, string(content))
+				}
+			}
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
@@ -2,21 +2,23 @@ package bed
 
 import (
 	"sort"
-	"strings"
 
 	"github.com/maruel/natural"
 )
 
-// Remove duplicated lines
+// Remove duplicated lines, the comments of removed
+// lines are kept in front of the first line
 func (bf *Bedfile) DeduplicateLines() {
 	var deduplicatedLines []Line
-	seen := map[string]bool{}
+	seen := map[string]int{}
 	for _, line := range bf.Lines {
-		joinedLine := strings.Join(line.Full, ",")
-		if !seen[joinedLine] {
-			seen[joinedLine] = true
-			deduplicatedLines = append(deduplicatedLines, line)
+		joinedLine := line.text()
+		if i, ok := seen[joinedLine]; ok {
+			deduplicatedLines[i].Comments = append(deduplicatedLines[i].Comments, line.Comments...)
+			continue
 		}
+		seen[joinedLine] = len(deduplicatedLines)
+		deduplicatedLines = append(deduplicatedLines, line)
 	}
 	bf.Lines = deduplicatedLines
 }
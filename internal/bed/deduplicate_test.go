@@ -22,27 +22,21 @@ func TestDeduplicateLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
-						Full: []string{"2", "20", "200"},
 					},
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 				},
 			},
@@ -51,19 +45,15 @@ func TestDeduplicateLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
-						Full: []string{"2", "20", "200"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 				},
 			},
@@ -78,22 +68,22 @@ func TestDeduplicateLines(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 				},
 			},
@@ -105,22 +95,22 @@ func TestDeduplicateLines(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 				},
 			},
@@ -0,0 +1,273 @@
+package bed
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"unsafe"
+)
+
+// Sorting, merging and padding bed files that do not fit into memory
+//
+// The input is read in chunks of at most --max-memory bytes. Each chunk
+// is sorted according to --sort-type and written to a temporary file in
+// --tmp-dir. The chunks are then k-way merged and passed on to be padded,
+// merged/deduplicated and written region by region.
+func (bf *Bedfile) ExternalSort() error {
+	if err := bf.readChrAliasFile(); err != nil {
+		return err
+	}
+	// The fasta index is needed up front for padding and fidx sorting
+	if err := bf.readFastaIdxFile(); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(bf.TmpDir, "bedfusion-")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Split the input into sorted chunks
+	//
+	// The lines are padded before they are sorted, as lines on different
+	// strands can be padded differently (see --pad-upstream)
+	var chunks []string
+	var chrNotInLengthMap []string
+	var expectedNrOfCols int
+	chunkSize := 0
+	for _, input := range bf.Inputs {
+		bedFile, err := openInput(input)
+		if err != nil {
+			return err
+		}
+		defer bedFile.Close()
+		bf.currentInput = input
+		err = bf.scanBed(bedFile, &expectedNrOfCols, func(l Line, _ int) error {
+			// The ##contig lines of VCF files come before the first region
+			if chunkSize == 0 && len(chunks) == 0 {
+				if err := bf.VerifyVcfChrLengths(); err != nil {
+					return err
+				}
+			}
+			if bf.HasPadding() {
+				var err error
+				l, chrNotInLengthMap, err = bf.padAccordingToPaddingType(l, chrNotInLengthMap)
+				if err != nil {
+					return err
+				}
+			}
+			bf.Lines = append(bf.Lines, l)
+			chunkSize += lineSize(l)
+			if chunkSize < bf.maxMemory {
+				return nil
+			}
+			chunk, err := bf.writeChunk(tmpDir, len(chunks))
+			chunks = append(chunks, chunk)
+			chunkSize = 0
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("can't read bed file %s: %q", input, err)
+		}
+	}
+	bf.chrAliasWarnings()
+	if len(bf.Lines) > 0 {
+		chunk, err := bf.writeChunk(tmpDir, len(chunks))
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return bf.openOutput(func(writer io.Writer) error {
+		return bf.mergeChunks(chunks, chrNotInLengthMap, writer)
+	})
+}
+
+// Sort the lines currently in memory and write them to a chunk file
+func (bf *Bedfile) writeChunk(tmpDir string, chunkNr int) (string, error) {
+	if err := bf.Sort(); err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(tmpDir, fmt.Sprintf("chunk-%d-*.bed", chunkNr))
+	if err != nil {
+		return "", fmt.Errorf("cannot create chunk file: %v", err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	for _, l := range bf.Lines {
+		for _, c := range l.Comments {
+			if _, err := fmt.Fprintf(writer, "%s\n", c); err != nil {
+				return "", err
+			}
+		}
+		if _, err := fmt.Fprintf(writer, "%s\n", l.text()); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+	bf.Lines = nil
+	return file.Name(), nil
+}
+
+// Merge the sorted chunk files and pass the
+// already padded lines on to be merged and written
+func (bf *Bedfile) mergeChunks(chunks []string, chrNotInLengthMap []string, writer io.Writer) error {
+	var readers []io.Reader
+	for _, chunk := range chunks {
+		file, err := os.Open(chunk)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+	sw, err := bf.newStreamWriter(writer)
+	if err != nil {
+		return err
+	}
+	sw.padded = true
+	sw.chrNotInLengthMap = chrNotInLengthMap
+	if err := bf.kWayMerge(readers, sw.handle); err != nil {
+		return err
+	}
+	return sw.close()
+}
+
+// K-way merge of sorted chunks
+//
+// Lines that are equal according to the sorting type are
+// passed on in the order of the chunks to keep the sorting stable
+func (bf *Bedfile) kWayMerge(chunks []io.Reader, handle func(l Line, lineNr int) error) error {
+	compare, err := bf.lineCompareFunc()
+	if err != nil {
+		return err
+	}
+	h := &chunkHeap{compare: compare}
+	for i, chunk := range chunks {
+		c := &chunkReader{idx: i, scanner: bufio.NewScanner(chunk)}
+		ok, err := bf.nextChunkLine(c)
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.readers = append(h.readers, c)
+		}
+	}
+	heap.Init(h)
+
+	lineNr := 0
+	for h.Len() > 0 {
+		lineNr++
+		c := h.readers[0]
+		if err := handle(c.line, lineNr); err != nil {
+			return err
+		}
+		ok, err := bf.nextChunkLine(c)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// Read the next line of a chunk, returns false if the chunk is empty
+//
+// Comments are written in front of the line they belong to
+func (bf *Bedfile) nextChunkLine(c *chunkReader) (bool, error) {
+	var comments []string
+	for c.scanner.Scan() {
+		if commentPattern.MatchString(c.scanner.Text()) {
+			comments = append(comments, c.scanner.Text())
+			continue
+		}
+		l, err := bf.lineFromText(c.scanner.Bytes())
+		if err != nil {
+			return false, fmt.Errorf("can't read chunk file: %v", err)
+		}
+		l.Comments = comments
+		c.line = l
+		return true, nil
+	}
+	return false, c.scanner.Err()
+}
+
+// Fill line struct from the text of an already verified line
+func (bf *Bedfile) lineFromText(text []byte) (Line, error) {
+	var err error
+	chr, rest, _ := bytes.Cut(text, []byte("\t"))
+	start, rest, _ := bytes.Cut(rest, []byte("\t"))
+	stop, opt, hasOpt := bytes.Cut(rest, []byte("\t"))
+	l := Line{Chr: bf.intern(chr)}
+	l.Start, err = strconv.Atoi(string(start))
+	if err != nil {
+		return Line{}, err
+	}
+	l.Stop, err = strconv.Atoi(string(stop))
+	if err != nil {
+		return Line{}, err
+	}
+	if hasOpt {
+		l.Opt = bytes.Clone(opt)
+	}
+	if bf.StrandCol > stopIdx {
+		strand, _ := nthField(l.Opt, bf.StrandCol-firstOptIdx)
+		l.Strand = bf.intern(strand)
+	}
+	if bf.FeatCol > stopIdx {
+		feat, _ := nthField(l.Opt, bf.FeatCol-firstOptIdx)
+		l.Feat = bf.intern(feat)
+	}
+	return l, nil
+}
+
+// Approximate number of bytes used by a line in memory, the
+// interned chromosomes, strands and features are not counted
+func lineSize(l Line) int {
+	return int(unsafe.Sizeof(l)) + cap(l.Opt)
+}
+
+// Reader of a sorted chunk file keeping track of its current line
+type chunkReader struct {
+	idx     int
+	scanner *bufio.Scanner
+	line    Line
+}
+
+// Min-heap of chunk readers ordered by their current line
+type chunkHeap struct {
+	readers []*chunkReader
+	compare func(a, b Line) int
+}
+
+func (h chunkHeap) Len() int { return len(h.readers) }
+
+func (h chunkHeap) Less(i, j int) bool {
+	if order := h.compare(h.readers[i].line, h.readers[j].line); order != 0 {
+		return order < 0
+	}
+	return h.readers[i].idx < h.readers[j].idx
+}
+
+func (h chunkHeap) Swap(i, j int) { h.readers[i], h.readers[j] = h.readers[j], h.readers[i] }
+
+func (h *chunkHeap) Push(x any) { h.readers = append(h.readers, x.(*chunkReader)) }
+
+func (h *chunkHeap) Pop() any {
+	old := h.readers
+	c := old[len(old)-1]
+	h.readers = slices.Delete(old, len(old)-1, len(old))
+	return c
+}
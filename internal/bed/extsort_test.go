@@ -0,0 +1,150 @@
+package bed
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestKWayMerge(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bed           Bedfile
+		chunks        []string
+		expectedLines []Line
+	}
+	testCases := []testCase{
+		{
+			testing: "lexicographic sorting",
+			bed: Bedfile{
+				SortType: LexST,
+			},
+			chunks: []string{
+				"1\t10\t20\n" +
+					"2\t5\t8\n",
+				"1\t5\t8\n" +
+					"10\t1\t2\n",
+			},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 5, Stop: 8,
+				},
+				{
+					Chr: "1", Start: 10, Stop: 20,
+				},
+				{
+					Chr: "10", Start: 1, Stop: 2,
+				},
+				{
+					Chr: "2", Start: 5, Stop: 8,
+				},
+			},
+		},
+		{
+			testing: "natural sorting, equal lines keep chunk order",
+			bed: Bedfile{
+				SortType: NatST,
+				FeatCol:  4 - 1,
+			},
+			chunks: []string{
+				"2\t5\t8\tA\tfirst\n",
+				"1\t5\t8\tA\tsecond\n" +
+					"2\t5\t8\tA\tsecond\n",
+				"",
+			},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 5, Stop: 8, Feat: "A",
+					Opt: []byte("A\tsecond"),
+				},
+				{
+					Chr: "2", Start: 5, Stop: 8, Feat: "A",
+					Opt: []byte("A\tfirst"),
+				},
+				{
+					Chr: "2", Start: 5, Stop: 8, Feat: "A",
+					Opt: []byte("A\tsecond"),
+				},
+			},
+		},
+		{
+			testing: "custom chromosome sorting",
+			bed: Bedfile{
+				SortType:    CcsST,
+				chrOrderMap: chrOrderToMap([]string{"X", "1"}),
+			},
+			chunks: []string{
+				"X\t5\t8\n" +
+					"2\t1\t2\n",
+				"1\t1\t2\n",
+			},
+			expectedLines: []Line{
+				{
+					Chr: "X", Start: 5, Stop: 8,
+				},
+				{
+					Chr: "1", Start: 1, Stop: 2,
+				},
+				{
+					Chr: "2", Start: 1, Stop: 2,
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var chunks []io.Reader
+			for _, chunk := range tc.chunks {
+				chunks = append(chunks, strings.NewReader(chunk))
+			}
+			var lines []Line
+			err := tc.bed.kWayMerge(chunks, func(l Line, _ int) error {
+				lines = append(lines, l)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tc.expectedLines, lines); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
+
+func TestParseMemorySize(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		size         string
+		expectedSize int
+		shouldFail   bool
+	}
+	testCases := []testCase{
+		{size: "1024", expectedSize: 1024},
+		{size: "10K", expectedSize: 10 * 1024},
+		{size: "500M", expectedSize: 500 * 1024 * 1024},
+		{size: "4g", expectedSize: 4 * 1024 * 1024 * 1024},
+		{size: "4GB", expectedSize: 4 * 1024 * 1024 * 1024},
+		{size: "4X", shouldFail: true},
+		{size: "G", shouldFail: true},
+		{size: "0", shouldFail: true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.size, func(t *testing.T) {
+			t.Parallel()
+			size, err := ParseMemorySize(tc.size)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if size != tc.expectedSize {
+				t.Errorf("expected %d got %d", tc.expectedSize, size)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package bed
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// One sequence of a fasta file, with the
+// columns of the samtools fasta index file
+type faiRecord struct {
+	name      string
+	length    int   // number of bases
+	offset    int64 // byte offset of the first base
+	lineBases int   // number of bases on each line
+	lineWidth int   // number of bytes on each line, including the newline
+
+	// Line number of the first line after a line that is shorter
+	// than the first line of the sequence, 0 if there is none.
+	// Sequences with such lines can not be indexed
+	irregularLineNr int
+}
+
+// Verify the fasta combinations, and use the fasta file as the source of
+// the chromosome lengths everywhere the fasta index file would be used
+func (bf *Bedfile) verifyAndHandleFasta() error {
+	if bf.Fasta != "" && bf.FastaIdx != "" {
+		return fmt.Errorf("--fasta can not be used together with --fasta-idx")
+	}
+	if bf.WriteFai && bf.Fasta == "" {
+		return fmt.Errorf("--write-fai must be used together with --fasta")
+	}
+	if bf.Fasta != "" {
+		bf.FastaIdx = bf.Fasta
+	}
+	return nil
+}
+
+// Scanning the fasta file for the chromosome sizes, and writing
+// the fasta index file next to it if --write-fai is set
+func (bf *Bedfile) readFastaFile() error {
+	fastaFile, err := openInput(bf.Fasta)
+	if err != nil {
+		return err
+	}
+	defer fastaFile.Close()
+	// The offsets of the index refer to the uncompressed
+	// content, so compressed fasta files can not be indexed
+	if bf.WriteFai && fastaFile.(*inputFile).gzip != nil {
+		return fmt.Errorf("--write-fai can not be used with compressed fasta files: %s", bf.Fasta)
+	}
+	records, err := readFasta(fastaFile)
+	if err != nil {
+		return fmt.Errorf("can't read fasta file %s: %q", bf.Fasta, err)
+	}
+	if bf.WriteFai {
+		if err := writeFaiFile(bf.Fasta+".fai", records); err != nil {
+			return err
+		}
+	}
+	chrOrder, chrLengthMap := bf.faiChrLengths(records)
+	return bf.setChrLengths(chrOrder, chrLengthMap)
+}
+
+// Chromosome order and sizes of the sequences of a fasta file
+func (bf *Bedfile) faiChrLengths(records []faiRecord) ([]string, map[string]int) {
+	var chrOrder []string
+	chrLengthMap := map[string]int{}
+	for _, rec := range records {
+		chr := bf.resolveChr(rec.name)
+		chrLengthMap[chr] = rec.length
+		chrOrder = append(chrOrder, chr)
+	}
+	return chrOrder, chrLengthMap
+}
+
+// Reading the sequences of a fasta file. The sequence name is the first
+// word of the header line (>), and the sequence lines are only counted,
+// so that sequences are never kept in memory
+func readFasta(file io.Reader) ([]faiRecord, error) {
+	var records []faiRecord
+	var rec *faiRecord
+	// A line shorter than the first line of the sequence has been read,
+	// meaning that the following line must be the end of the sequence
+	var short bool
+
+	var offset int64
+	lineNr := 0
+	reader := bufio.NewReader(file)
+	for {
+		header, bases, width, err := readFastaLine(reader)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if width == 0 {
+			break
+		}
+		lineNr++
+		offset += int64(width)
+
+		switch {
+		case header != nil:
+			name, _, _ := strings.Cut(strings.TrimSpace(string(header[1:])), " ")
+			name, _, _ = strings.Cut(name, "\t")
+			if name == "" {
+				return nil, fmt.Errorf("missing sequence name on line %d", lineNr)
+			}
+			records = append(records, faiRecord{name: name, offset: offset})
+			rec = &records[len(records)-1]
+			short = false
+			continue
+		case rec == nil:
+			if bases != 0 {
+				return nil, fmt.Errorf("expected a header line (>) before the sequence on line %d", lineNr)
+			}
+			continue
+		case rec.lineWidth == 0 && !short:
+			if bases == 0 {
+				short = true
+				continue
+			}
+			rec.lineBases = bases
+			rec.lineWidth = width
+		default:
+			if short && bases != 0 && rec.irregularLineNr == 0 {
+				rec.irregularLineNr = lineNr
+			}
+			if bases != rec.lineBases || width != rec.lineWidth {
+				short = true
+			}
+		}
+		rec.length += bases
+	}
+	return records, nil
+}
+
+// Reading one line of a fasta file, returning the line only if it
+// is a header line, together with the number of bases and bytes on
+// the line. The sequence lines are not kept, so that sequences on a
+// single line do not need to fit in memory
+func readFastaLine(reader *bufio.Reader) (header []byte, bases int, width int, err error) {
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if width == 0 && len(chunk) > 0 && chunk[0] == '>' {
+			header = []byte{}
+		}
+		if header != nil {
+			header = append(header, chunk...)
+		}
+		width += len(chunk)
+		bases += len(chunk) - bytes.Count(chunk, []byte("\n")) - bytes.Count(chunk, []byte("\r"))
+		if err != bufio.ErrBufferFull {
+			return header, bases, width, err
+		}
+	}
+}
+
+// Write the fasta index file in the samtools format
+func writeFai(w io.Writer, records []faiRecord) error {
+	for _, rec := range records {
+		if rec.irregularLineNr != 0 {
+			return fmt.Errorf("sequence %s has lines of different lengths (line %d), so it can not be indexed",
+				rec.name, rec.irregularLineNr)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n",
+			rec.name, rec.length, rec.offset, rec.lineBases, rec.lineWidth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write the fasta index file to path
+func writeFaiFile(path string, records []faiRecord) error {
+	var buf bytes.Buffer
+	if err := writeFai(&buf, records); err != nil {
+		return fmt.Errorf("can't write fasta index file %s: %q", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("can't write fasta index file %s: %q", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package bed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestVerifyAndHandleFasta(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing     string
+		bed         Bedfile
+		expectedBed Bedfile
+		shouldFail  bool
+	}
+	testCases := []testCase{
+		{
+			testing: "fasta file is used as the fasta index file",
+			bed: Bedfile{
+				Fasta:    "/some/path/ref.fasta",
+				WriteFai: true,
+			},
+			expectedBed: Bedfile{
+				Fasta:    "/some/path/ref.fasta",
+				FastaIdx: "/some/path/ref.fasta",
+				WriteFai: true,
+			},
+		},
+		{
+			testing: "neither fasta nor write-fai set",
+			bed: Bedfile{
+				FastaIdx: "/some/path/ref.fasta.fai",
+			},
+			expectedBed: Bedfile{
+				FastaIdx: "/some/path/ref.fasta.fai",
+			},
+		},
+		{
+			testing: "both fasta and fasta-idx set",
+			bed: Bedfile{
+				Fasta:    "/some/path/ref.fasta",
+				FastaIdx: "/some/path/ref.fasta.fai",
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "write-fai without fasta",
+			bed: Bedfile{
+				FastaIdx: "/some/path/ref.fasta.fai",
+				WriteFai: true,
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bed.verifyAndHandleFasta()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedBed, tc.bed); diff != nil {
+					t.Error("expected VS received bed", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestReadFasta(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		fastaContent    string
+		expectedRecords []faiRecord
+		shouldFail      bool
+	}
+	testCases := []testCase{
+		{
+			testing: "sequences on several lines",
+			fastaContent: ">1 first chromosome\n" +
+				"ACGTACGT\n" +
+				"ACGTACGT\n" +
+				"ACG\n" +
+				">2\n" +
+				"ACGTACGT\n",
+			expectedRecords: []faiRecord{
+				{name: "1", length: 19, offset: 20, lineBases: 8, lineWidth: 9},
+				{name: "2", length: 8, offset: 45, lineBases: 8, lineWidth: 9},
+			},
+		},
+		{
+			testing: "windows line endings and no newline at the end",
+			fastaContent: ">chr1\tdescription\r\n" +
+				"ACGT\r\n" +
+				"AC",
+			expectedRecords: []faiRecord{
+				{name: "chr1", length: 6, offset: 19, lineBases: 4, lineWidth: 6},
+			},
+		},
+		{
+			testing: "sequence on a single line longer than the read buffer",
+			fastaContent: ">1\n" +
+				strings.Repeat("N", 10_000) + "\n" +
+				">2\n" +
+				"A\n",
+			expectedRecords: []faiRecord{
+				{name: "1", length: 10_000, offset: 3, lineBases: 10_000, lineWidth: 10_001},
+				{name: "2", length: 1, offset: 10_007, lineBases: 1, lineWidth: 2},
+			},
+		},
+		{
+			testing: "empty sequence and empty lines between sequences",
+			fastaContent: ">1\n" +
+				">2\n" +
+				"ACGT\n" +
+				"AC\n" +
+				"\n" +
+				">3\n" +
+				"A\n",
+			expectedRecords: []faiRecord{
+				{name: "1", offset: 3},
+				{name: "2", length: 6, offset: 6, lineBases: 4, lineWidth: 5},
+				{name: "3", length: 1, offset: 18, lineBases: 1, lineWidth: 2},
+			},
+		},
+		{
+			testing: "line after a shorter line",
+			fastaContent: ">1\n" +
+				"ACGT\n" +
+				"AC\n" +
+				"ACGT\n",
+			expectedRecords: []faiRecord{
+				{name: "1", length: 10, offset: 3, lineBases: 4, lineWidth: 5, irregularLineNr: 4},
+			},
+		},
+		{
+			testing: "line after an empty line",
+			fastaContent: ">1\n" +
+				"ACGT\n" +
+				"\n" +
+				"ACGT\n",
+			expectedRecords: []faiRecord{
+				{name: "1", length: 8, offset: 3, lineBases: 4, lineWidth: 5, irregularLineNr: 4},
+			},
+		},
+		{
+			testing: "longer line than the first line",
+			fastaContent: ">1\n" +
+				"ACGT\n" +
+				"ACGTACGT\n" +
+				"ACGT\n",
+			expectedRecords: []faiRecord{
+				{name: "1", length: 16, offset: 3, lineBases: 4, lineWidth: 5, irregularLineNr: 4},
+			},
+		},
+		{
+			testing:      "sequence before the first header line",
+			fastaContent: "ACGT\n>1\nACGT\n",
+			shouldFail:   true,
+		},
+		{
+			testing:      "header line without name",
+			fastaContent: "> \nACGT\n",
+			shouldFail:   true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			records, err := readFasta(strings.NewReader(tc.fastaContent))
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedRecords, records); diff != nil {
+					t.Error("expected VS received records", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFai(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing     string
+		records     []faiRecord
+		expectedFai string
+		shouldFail  bool
+	}
+	testCases := []testCase{
+		{
+			testing: "samtools format",
+			records: []faiRecord{
+				{name: "1", length: 19, offset: 20, lineBases: 8, lineWidth: 9},
+				{name: "2", length: 8, offset: 45, lineBases: 8, lineWidth: 9},
+			},
+			expectedFai: "1\t19\t20\t8\t9\n" +
+				"2\t8\t45\t8\t9\n",
+		},
+		{
+			testing: "sequence with lines of different lengths",
+			records: []faiRecord{
+				{name: "1", length: 10, offset: 3, lineBases: 4, lineWidth: 5, irregularLineNr: 4},
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			err := writeFai(&buf, tc.records)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedFai, buf.String()); diff != nil {
+					t.Error("expected VS received fasta index file", diff)
+				}
+			}
+		})
+	}
+}
+
+// Compressed fasta files are scanned after decompressing them, and the
+// offsets of the written fasta index file are in the decompressed file
+func TestReadFastaFile(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing     string
+		compressed  bool
+		writeFai    bool
+		expectedFai string
+		shouldFail  bool
+	}
+	testCases := []testCase{
+		{
+			testing:     "fasta index file written next to the fasta file",
+			writeFai:    true,
+			expectedFai: "2\t11\t3\t8\t9\n1\t4\t19\t4\t5\n",
+		},
+		{
+			testing:    "compressed fasta file",
+			compressed: true,
+		},
+		{
+			testing:    "fasta index file for compressed fasta file",
+			compressed: true,
+			writeFai:   true,
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			content := []byte(">2\nACGTACGT\nACG\n>1\nACGT\n")
+			fasta := filepath.Join(t.TempDir(), "ref.fasta")
+			if tc.compressed {
+				var compressed bytes.Buffer
+				zw := gzip.NewWriter(&compressed)
+				zw.Write(content)
+				if err := zw.Close(); err != nil {
+					t.Fatal(err)
+				}
+				content = compressed.Bytes()
+				fasta += ".gz"
+			}
+			if err := os.WriteFile(fasta, content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			bf := Bedfile{Fasta: fasta, WriteFai: tc.writeFai, SortType: FidxST}
+			if err := bf.verifyAndHandleFasta(); err != nil {
+				t.Fatal(err)
+			}
+			err := bf.readFastaIdxFile()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if tc.shouldFail {
+				if _, err := os.Stat(fasta + ".fai"); err == nil {
+					t.Error("expected no fasta index file")
+				}
+				return
+			}
+			if diff := deep.Equal(map[string]int{"1": 4, "2": 11}, bf.chrLengthMap); diff != nil {
+				t.Error("expected VS received chromosome lengths", diff)
+			}
+			if diff := deep.Equal(map[string]int{"2": 1, "1": 2}, bf.chrOrderMap); diff != nil {
+				t.Error("expected VS received chromosome order", diff)
+			}
+			if tc.writeFai {
+				fai, err := os.ReadFile(fasta + ".fai")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := deep.Equal(tc.expectedFai, string(fai)); diff != nil {
+					t.Error("expected VS received fasta index file", diff)
+				}
+			}
+		})
+	}
+}
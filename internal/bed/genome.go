@@ -0,0 +1,193 @@
+package bed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Magic bytes at the start of the (decompressed) BAM file
+var bamMagic = []byte("BAM\x01")
+
+// Longest reference name accepted in a BAM header, to avoid
+// allocating huge buffers when reading a corrupt file
+const maxBamRefNameLength = 1 << 16
+
+// Reading the chromosome order and sizes from a genome file, the
+// format is detected from the content of the file:
+//   - BAM files are recognized by their magic bytes, and the
+//     reference sequences of the header are used
+//   - fasta files start with a header line (>), and
+//     the sequences are scanned to find their sizes
+//   - Picard/GATK sequence dictionaries (.dict) and SAM files
+//     start with a header line (@), and the @SQ lines are used
+//   - everything else is read as a tab separated file with the
+//     chromosome and its size in the first two columns, such as
+//     fasta index files and UCSC chrom.sizes files
+func (bf *Bedfile) readGenome(file io.Reader) ([]string, map[string]int, error) {
+	reader := bufio.NewReader(file)
+	start, err := reader.Peek(len(bamMagic))
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	switch {
+	case bytes.Equal(start, bamMagic):
+		return bf.readBamHeader(reader)
+	case bytes.HasPrefix(start, []byte(">")):
+		records, err := readFasta(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		chrOrder, chrLengthMap := bf.faiChrLengths(records)
+		return chrOrder, chrLengthMap, nil
+	case bytes.HasPrefix(start, []byte("@")):
+		return bf.readSamHeader(reader)
+	default:
+		return bf.readChrSizes(reader)
+	}
+}
+
+// Reading a tab separated file with at least two columns where the
+// first column contains the chromosome and the second it's size
+func (bf *Bedfile) readChrSizes(file io.Reader) ([]string, map[string]int, error) {
+	var chrOrder []string
+
+	minNrCols := 2
+	chrLengthMap := map[string]int{}
+
+	const (
+		chrFIdx  = 0
+		sizeFIdx = 1
+	)
+
+	lineNr := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNr++
+
+		lineText := scanner.Text()
+
+		// Split line
+		cols := strings.Split(lineText, "\t")
+
+		// For the first content line set the number of columns if it is empty
+		if len(cols) < minNrCols {
+			return nil, nil, fmt.Errorf("expected at least %d columns on line %d got %d: %s",
+				minNrCols, lineNr, len(cols), lineText)
+		}
+
+		// Put chromosome sizes in map and record chromosome order
+		size, err := strconv.Atoi(cols[sizeFIdx])
+		if err != nil {
+			return nil, nil, fmt.Errorf("non-int size for chr %s on line %d: %s", cols[chrFIdx], lineNr, cols[sizeFIdx])
+		}
+		chr := bf.resolveChr(cols[chrFIdx])
+		chrLengthMap[chr] = size
+		chrOrder = append(chrOrder, chr)
+	}
+	return chrOrder, chrLengthMap, scanner.Err()
+}
+
+// Reading the @SQ lines of a Picard/GATK sequence dictionary or a
+// SAM header, where the SN tag contains the chromosome and the LN
+// tag it's size. Reading stops at the first line that is not a
+// header line, so that the alignments of SAM files are skipped
+func (bf *Bedfile) readSamHeader(file io.Reader) ([]string, map[string]int, error) {
+	var chrOrder []string
+	chrLengthMap := map[string]int{}
+
+	lineNr := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNr++
+
+		lineText := scanner.Text()
+		if !strings.HasPrefix(lineText, "@") {
+			break
+		}
+		if !strings.HasPrefix(lineText, "@SQ\t") {
+			continue
+		}
+
+		var name, length string
+		for _, tag := range strings.Split(lineText, "\t")[1:] {
+			if value, ok := strings.CutPrefix(tag, "SN:"); ok {
+				name = value
+			} else if value, ok := strings.CutPrefix(tag, "LN:"); ok {
+				length = value
+			}
+		}
+		if name == "" || length == "" {
+			return nil, nil, fmt.Errorf("expected both a SN and a LN tag on line %d: %s", lineNr, lineText)
+		}
+		size, err := strconv.Atoi(length)
+		if err != nil {
+			return nil, nil, fmt.Errorf("non-int size for chr %s on line %d: %s", name, lineNr, length)
+		}
+		chr := bf.resolveChr(name)
+		chrLengthMap[chr] = size
+		chrOrder = append(chrOrder, chr)
+	}
+	return chrOrder, chrLengthMap, scanner.Err()
+}
+
+// Reading the reference sequences from the header of a decompressed
+// BAM file. The header consists of the magic bytes, the header text,
+// and the number of reference sequences followed by the name and
+// length of each of them, all integers being little-endian int32
+func (bf *Bedfile) readBamHeader(file io.Reader) ([]string, map[string]int, error) {
+	var chrOrder []string
+	chrLengthMap := map[string]int{}
+
+	readInt := func(what string) (int, error) {
+		var i int32
+		if err := binary.Read(file, binary.LittleEndian, &i); err != nil {
+			return 0, fmt.Errorf("could not read the %s of the BAM header: %v", what, err)
+		}
+		if i < 0 {
+			return 0, fmt.Errorf("negative %s in the BAM header: %d", what, i)
+		}
+		return int(i), nil
+	}
+
+	if _, err := io.CopyN(io.Discard, file, int64(len(bamMagic))); err != nil {
+		return nil, nil, err
+	}
+	textLength, err := readInt("header text length")
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.CopyN(io.Discard, file, int64(textLength)); err != nil {
+		return nil, nil, fmt.Errorf("could not read the header text of the BAM header: %v", err)
+	}
+	nrOfRefs, err := readInt("number of reference sequences")
+	if err != nil {
+		return nil, nil, err
+	}
+	for range nrOfRefs {
+		nameLength, err := readInt("reference name length")
+		if err != nil {
+			return nil, nil, err
+		}
+		if nameLength > maxBamRefNameLength {
+			return nil, nil, fmt.Errorf("reference name length in the BAM header is too long: %d", nameLength)
+		}
+		name := make([]byte, nameLength)
+		if _, err := io.ReadFull(file, name); err != nil {
+			return nil, nil, fmt.Errorf("could not read the reference name of the BAM header: %v", err)
+		}
+		size, err := readInt("reference length")
+		if err != nil {
+			return nil, nil, err
+		}
+		// The name is NUL terminated
+		chr := bf.resolveChr(string(bytes.TrimRight(name, "\x00")))
+		chrLengthMap[chr] = size
+		chrOrder = append(chrOrder, chr)
+	}
+	return chrOrder, chrLengthMap, nil
+}
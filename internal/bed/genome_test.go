@@ -0,0 +1,193 @@
+package bed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// Make the start of a decompressed BAM file, with the header
+// text and the reference sequences in refs
+func bamHeader(text string, refs []string, sizes []int32) []byte {
+	var buf bytes.Buffer
+	buf.Write(bamMagic)
+	binary.Write(&buf, binary.LittleEndian, int32(len(text)))
+	buf.WriteString(text)
+	binary.Write(&buf, binary.LittleEndian, int32(len(refs)))
+	for i, ref := range refs {
+		binary.Write(&buf, binary.LittleEndian, int32(len(ref)+1))
+		buf.WriteString(ref + "\x00")
+		binary.Write(&buf, binary.LittleEndian, sizes[i])
+	}
+	return buf.Bytes()
+}
+
+func TestReadGenome(t *testing.T) {
+	t.Parallel()
+	samHeaderText := "@HD\tVN:1.6\tSO:coordinate\n" +
+		"@SQ\tSN:1\tLN:249250621\n" +
+		"@SQ\tSN:2\tLN:243199373\n"
+	type testCase struct {
+		testing              string
+		bed                  Bedfile
+		genomeFileContent    []byte
+		expectedChrOrder     []string
+		expectedChrLengthMap map[string]int
+		shouldFail           bool
+	}
+	testCases := []testCase{
+		{
+			testing:           "UCSC chrom.sizes file",
+			genomeFileContent: []byte("chr1\t248956422\nchr2\t242193529\n"),
+			expectedChrOrder:  []string{"chr1", "chr2"},
+			expectedChrLengthMap: map[string]int{
+				"chr1": 248956422,
+				"chr2": 242193529,
+			},
+		},
+		{
+			testing: "Picard sequence dictionary",
+			genomeFileContent: []byte("@HD\tVN:1.0\tSO:unsorted\n" +
+				"@SQ\tSN:1\tLN:249250621\tM5:1b22b98cdeb4a9304cb5d48026a85128\tUR:file:/ref/human_g1k_v37.fasta\n" +
+				"@SQ\tLN:243199373\tSN:2\n"),
+			expectedChrOrder: []string{"1", "2"},
+			expectedChrLengthMap: map[string]int{
+				"1": 249250621,
+				"2": 243199373,
+			},
+		},
+		{
+			testing: "SAM file with alignments",
+			genomeFileContent: []byte(samHeaderText +
+				"@PG\tID:bwa\tPN:bwa\n" +
+				"read1\t0\t1\t100\t60\t10M\t*\t0\t0\tACGTACGTAC\t*\n"),
+			expectedChrOrder: []string{"1", "2"},
+			expectedChrLengthMap: map[string]int{
+				"1": 249250621,
+				"2": 243199373,
+			},
+		},
+		{
+			testing:           "fasta file",
+			genomeFileContent: []byte(">1\nACGTACGT\nACG\n>2 description\nACGT\n"),
+			expectedChrOrder:  []string{"1", "2"},
+			expectedChrLengthMap: map[string]int{
+				"1": 11,
+				"2": 4,
+			},
+		},
+		{
+			testing:           "BAM header",
+			genomeFileContent: bamHeader(samHeaderText, []string{"1", "2"}, []int32{249250621, 243199373}),
+			expectedChrOrder:  []string{"1", "2"},
+			expectedChrLengthMap: map[string]int{
+				"1": 249250621,
+				"2": 243199373,
+			},
+		},
+		{
+			testing:           "BAM header without header text",
+			genomeFileContent: bamHeader("", []string{"chrM"}, []int32{16569}),
+			expectedChrOrder:  []string{"chrM"},
+			expectedChrLengthMap: map[string]int{
+				"chrM": 16569,
+			},
+		},
+		{
+			testing: "chromosome aliases are resolved",
+			bed: Bedfile{
+				chrAliases: map[string]string{
+					"chr1": "1",
+				},
+			},
+			genomeFileContent: bamHeader("", []string{"chr1"}, []int32{248956422}),
+			expectedChrOrder:  []string{"1"},
+			expectedChrLengthMap: map[string]int{
+				"1": 248956422,
+			},
+		},
+		{
+			testing:              "SAM header without any @SQ lines",
+			genomeFileContent:    []byte("@HD\tVN:1.6\n"),
+			expectedChrLengthMap: map[string]int{},
+		},
+		{
+			testing:           "@SQ line without LN",
+			genomeFileContent: []byte("@SQ\tSN:1\n"),
+			shouldFail:        true,
+		},
+		{
+			testing:           "@SQ line with non-int LN",
+			genomeFileContent: []byte("@SQ\tSN:1\tLN:long\n"),
+			shouldFail:        true,
+		},
+		{
+			testing:           "truncated BAM header",
+			genomeFileContent: bamHeader(samHeaderText, []string{"1", "2"}, []int32{249250621, 243199373})[:40],
+			shouldFail:        true,
+		},
+		{
+			testing:           "BAM header with negative reference length",
+			genomeFileContent: bamHeader("", []string{"1"}, []int32{-1}),
+			shouldFail:        true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			chrOrder, chrLengthMap, err := tc.bed.readGenome(bytes.NewReader(tc.genomeFileContent))
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedChrOrder, chrOrder); diff != nil {
+					t.Error("expected VS received chromosome order", diff)
+				}
+				if diff := deep.Equal(tc.expectedChrLengthMap, chrLengthMap); diff != nil {
+					t.Error("expected VS received chromosome lengths", diff)
+				}
+			}
+		})
+	}
+}
+
+// The BAM file is BGZF compressed, so the header is read after
+// decompressing it, and gives both the lengths and the fidx order
+func TestReadFastaIdxFromBam(t *testing.T) {
+	t.Parallel()
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(bamHeader("@HD\tVN:1.6\n", []string{"2", "1"}, []int32{243199373, 249250621}))
+	// The alignments after the header are not read
+	zw.Write(bytes.Repeat([]byte{0xff}, 100))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bf := Bedfile{SortType: FidxST}
+	if err := bf.ReadFastaIdxFrom("test.bam", &compressed); err != nil {
+		t.Fatal(err)
+	}
+	expectedBed := Bedfile{
+		FastaIdx: "test.bam",
+		SortType: FidxST,
+		chrLengthMap: map[string]int{
+			"1": 249250621,
+			"2": 243199373,
+		},
+		chrOrderMap: map[string]int{
+			"2": 1,
+			"1": 2,
+		},
+	}
+	if diff := deep.Equal(expectedBed, bf); diff != nil {
+		t.Error("expected VS received bed", diff)
+	}
+	if err := bf.readFastaIdx(strings.NewReader("@HD\tVN:1.6\n")); err == nil {
+		t.Error("expected a genome file without chromosomes to fail")
+	}
+}
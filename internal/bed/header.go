@@ -0,0 +1,104 @@
+package bed
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Header policies
+var FirstHP = "first"   // keep the header lines of the first file
+var ConcatHP = "concat" // keep the header lines of all files, without duplicates
+var DropHP = "drop"     // drop all header lines
+var TrackHP = "track"   // replace the header lines with a generated track line
+
+// Comment policies
+var KeepCP = "keep" // keep comments in place, in front of the region following them
+var TopCP = "top"   // move comments to the top, after the header lines
+var DropCP = "drop" // drop comments
+
+// Header lines (browser, track or #) and comment lines
+var headerPattern = regexp.MustCompile(`^(browser|track|#)`)
+var commentPattern = regexp.MustCompile(`^#`)
+
+// Verify header and comment policies, and generate
+// the track line if --header-policy=track
+//
+// Regions are written as soon as possible with --presorted, so header
+// lines and comments found after the first region can not be moved to
+// the top
+func (bf *Bedfile) verifyAndHandleHeaderPolicies() error {
+	if bf.TrackLine != "" && bf.HeaderPolicy != TrackHP {
+		return fmt.Errorf("--track-line must be used together with --header-policy=%s", TrackHP)
+	}
+	if bf.Presorted && bf.HeaderPolicy == ConcatHP {
+		return fmt.Errorf("--header-policy=%s can not be used together with --presorted", ConcatHP)
+	}
+	if bf.Presorted && bf.CommentPolicy == TopCP {
+		return fmt.Errorf("--comment-policy=%s can not be used together with --presorted", TopCP)
+	}
+	if bf.HeaderPolicy == TrackHP {
+		if bf.TrackLine != "" && !strings.HasPrefix(bf.TrackLine, "track") {
+			return fmt.Errorf("--track-line must start with track: %s", bf.TrackLine)
+		}
+		bf.Header = []string{bf.trackLine()}
+	}
+	return nil
+}
+
+// Track line given by --track-line, or the generated track line
+func (bf Bedfile) trackLine() string {
+	return cmp.Or(bf.TrackLine, bf.generatedTrackLine())
+}
+
+// Track line named after the output file, or bedfusion
+// if the output is written to standard output
+func (bf Bedfile) generatedTrackLine() string {
+	name := "bedfusion"
+	if bf.Output != "" {
+		name = filepath.Base(bf.Output)
+		for _, ext := range []string{".gz", ".bgz", ".bed"} {
+			name = strings.TrimSuffix(name, ext)
+		}
+	}
+	return fmt.Sprintf("track name=%q", name)
+}
+
+// Add header line of the file currently being read,
+// according to --header-policy
+func (bf *Bedfile) addHeader(header string, firstFile bool) {
+	switch bf.HeaderPolicy {
+	case ConcatHP:
+		if !slices.Contains(bf.Header, header) {
+			bf.Header = append(bf.Header, header)
+		}
+	case DropHP, TrackHP:
+	default:
+		if firstFile {
+			bf.Header = append(bf.Header, header)
+		}
+	}
+}
+
+// Handle comment found after the first region of the
+// file currently being read, according to --comment-policy
+func (bf *Bedfile) addComment(comment string) {
+	switch bf.CommentPolicy {
+	case TopCP:
+		bf.Header = append(bf.Header, comment)
+	case DropCP:
+	default:
+		bf.comments = append(bf.comments, comment)
+	}
+}
+
+// Returns the comments waiting for the next region, and
+// clears them so that they are only added to one region
+func (bf *Bedfile) takeComments() []string {
+	comments := bf.comments
+	bf.comments = nil
+	return comments
+}
@@ -0,0 +1,178 @@
+package bed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestVerifyAndHandleHeaderPolicies(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing        string
+		bf             Bedfile
+		expectedHeader []string
+		shouldFail     bool
+	}
+	testCases := []testCase{
+		{
+			testing: "keep the first header",
+			bf:      Bedfile{HeaderPolicy: FirstHP, CommentPolicy: KeepCP},
+		},
+		{
+			testing:        "generated track line named after the output file",
+			bf:             Bedfile{HeaderPolicy: TrackHP, Output: "/a/panel.bed.gz"},
+			expectedHeader: []string{`track name="panel"`},
+		},
+		{
+			testing:        "generated track line written to stdout",
+			bf:             Bedfile{HeaderPolicy: TrackHP},
+			expectedHeader: []string{`track name="bedfusion"`},
+		},
+		{
+			testing:        "given track line",
+			bf:             Bedfile{HeaderPolicy: TrackHP, TrackLine: "track name=panel color=0,0,255"},
+			expectedHeader: []string{"track name=panel color=0,0,255"},
+		},
+		{
+			testing:    "track line that is not a track line",
+			bf:         Bedfile{HeaderPolicy: TrackHP, TrackLine: "name=panel"},
+			shouldFail: true,
+		},
+		{
+			testing:    "track line without the track header policy",
+			bf:         Bedfile{HeaderPolicy: FirstHP, TrackLine: "track name=panel"},
+			shouldFail: true,
+		},
+		{
+			testing:    "concatenated headers with presorted input",
+			bf:         Bedfile{HeaderPolicy: ConcatHP, Presorted: true},
+			shouldFail: true,
+		},
+		{
+			testing:    "comments moved to the top with presorted input",
+			bf:         Bedfile{CommentPolicy: TopCP, Presorted: true},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bf.verifyAndHandleHeaderPolicies()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedHeader, tc.bf.Header); diff != nil {
+					t.Error("expected VS received header", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaderAndCommentPolicies(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing             string
+		bf                  Bedfile
+		expectedFileContent string
+	}
+	files := []string{
+		"track name=a\n" +
+			"#first file\n" +
+			"1\t10\t20\tA\n" +
+			"# about B\n" +
+			"1\t15\t30\tB\n" +
+			"# end of first file\n",
+		"browser position chr1\n" +
+			"track name=a\n" +
+			"1\t1\t5\tC\n" +
+			"track name=b\n" +
+			"# about D\n" +
+			"2\t1\t5\tD\n",
+	}
+	testCases := []testCase{
+		{
+			testing: "first header and comments kept in place",
+			bf:      Bedfile{HeaderPolicy: FirstHP, CommentPolicy: KeepCP, SortType: LexST},
+			expectedFileContent: "track name=a\n" +
+				"#first file\n" +
+				"# end of first file\n" +
+				"1\t1\t5\tC\n" +
+				"# about B\n" +
+				"1\t10\t30\tA,B\n" +
+				"# about D\n" +
+				"2\t1\t5\tD\n",
+		},
+		{
+			testing: "concatenated headers and comments moved to the top",
+			bf:      Bedfile{HeaderPolicy: ConcatHP, CommentPolicy: TopCP, SortType: LexST},
+			expectedFileContent: "track name=a\n" +
+				"#first file\n" +
+				"# about B\n" +
+				"# end of first file\n" +
+				"browser position chr1\n" +
+				"track name=b\n" +
+				"# about D\n" +
+				"1\t1\t5\tC\n" +
+				"1\t10\t30\tA,B\n" +
+				"2\t1\t5\tD\n",
+		},
+		{
+			testing: "headers and comments dropped",
+			bf:      Bedfile{HeaderPolicy: DropHP, CommentPolicy: DropCP, SortType: LexST},
+			expectedFileContent: "1\t1\t5\tC\n" +
+				"1\t10\t30\tA,B\n" +
+				"2\t1\t5\tD\n",
+		},
+		{
+			testing: "generated track line",
+			bf:      Bedfile{HeaderPolicy: TrackHP, CommentPolicy: DropCP, SortType: LexST},
+			expectedFileContent: "track name=\"bedfusion\"\n" +
+				"1\t1\t5\tC\n" +
+				"1\t10\t30\tA,B\n" +
+				"2\t1\t5\tD\n",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			if err := tc.bf.verifyAndHandleHeaderPolicies(); err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range files {
+				if err := tc.bf.readBed(strings.NewReader(file)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tc.bf.MergeAndPadLines(); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.bf.Sort(); err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(tc.expectedFileContent, tc.bf.toString()); diff != nil {
+				t.Error("expected VS received file content", diff)
+			}
+		})
+	}
+}
+
+func TestDeduplicateLinesKeepsComments(t *testing.T) {
+	t.Parallel()
+	bf := Bedfile{Lines: []Line{
+		{Chr: "1", Start: 1, Stop: 5, Comments: []string{"# a"}},
+		{Chr: "1", Start: 1, Stop: 5, Comments: []string{"# b"}},
+	}}
+	bf.DeduplicateLines()
+	expectedLines := []Line{
+		{Chr: "1", Start: 1, Stop: 5, Comments: []string{"# a", "# b"}},
+	}
+	if diff := deep.Equal(expectedLines, bf.Lines); diff != nil {
+		t.Error("expected VS received lines", diff)
+	}
+}
@@ -0,0 +1,104 @@
+package bed
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+)
+
+// Index of regions for finding overlaps
+//
+// The regions on each chromosome are sorted by start, and for each
+// region the largest stop up to and including that region is kept.
+// This makes it possible to find the overlapping regions with a
+// binary search followed by a walk backwards that stops as soon as
+// no earlier region can reach the query.
+type regionIndex struct {
+	chrs map[string]*chrRegions
+}
+
+// Regions on a single chromosome
+type chrRegions struct {
+	lines   []Line
+	idxs    []int // position of each region in the indexed lines
+	maxStop []int
+}
+
+// Create new region index
+func newRegionIndex(lines []Line) *regionIndex {
+	ri := &regionIndex{chrs: map[string]*chrRegions{}}
+	for i, l := range lines {
+		chr, ok := ri.chrs[l.Chr]
+		if !ok {
+			chr = &chrRegions{}
+			ri.chrs[l.Chr] = chr
+		}
+		chr.idxs = append(chr.idxs, i)
+	}
+	for _, chr := range ri.chrs {
+		slices.SortStableFunc(chr.idxs, func(a, b int) int {
+			return cmp.Or(
+				cmp.Compare(lines[a].Start, lines[b].Start),
+				cmp.Compare(lines[a].Stop, lines[b].Stop),
+			)
+		})
+		chr.lines = make([]Line, len(chr.idxs))
+		chr.maxStop = make([]int, len(chr.idxs))
+		for i, idx := range chr.idxs {
+			l := lines[idx]
+			chr.lines[i] = l
+			chr.maxStop[i] = l.Stop
+			if i > 0 && chr.maxStop[i-1] > l.Stop {
+				chr.maxStop[i] = chr.maxStop[i-1]
+			}
+		}
+	}
+	return ri
+}
+
+// Returns the regions overlapping start and stop on the chromosome,
+// sorted by start and stop. Regions are overlapping if they share
+// at least one base.
+func (ri *regionIndex) overlapping(chr string, start, stop int) []Line {
+	regions, ok := ri.chrs[chr]
+	if !ok {
+		return nil
+	}
+	var overlapping []Line
+	for _, i := range regions.overlapping(start, stop) {
+		overlapping = append(overlapping, regions.lines[i])
+	}
+	return overlapping
+}
+
+// Returns the positions in the indexed lines of the regions
+// overlapping start and stop on the chromosome, sorted by
+// start and stop
+func (ri *regionIndex) overlappingIdxs(chr string, start, stop int) []int {
+	regions, ok := ri.chrs[chr]
+	if !ok {
+		return nil
+	}
+	var overlapping []int
+	for _, i := range regions.overlapping(start, stop) {
+		overlapping = append(overlapping, regions.idxs[i])
+	}
+	return overlapping
+}
+
+// Returns the positions in the sorted regions of the
+// regions overlapping start and stop
+func (c *chrRegions) overlapping(start, stop int) []int {
+	// The first region starting at or after stop can not overlap
+	end := sort.Search(len(c.lines), func(i int) bool {
+		return c.lines[i].Start >= stop
+	})
+	var overlapping []int
+	for i := end - 1; i >= 0 && c.maxStop[i] > start; i-- {
+		if c.lines[i].Stop > start {
+			overlapping = append(overlapping, i)
+		}
+	}
+	slices.Reverse(overlapping)
+	return overlapping
+}
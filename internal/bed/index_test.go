@@ -0,0 +1,85 @@
+package bed
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestRegionIndexOverlapping(t *testing.T) {
+	t.Parallel()
+	lines := []Line{
+		{Chr: "1", Start: 50, Stop: 60},
+		{Chr: "1", Start: 0, Stop: 1000},
+		{Chr: "1", Start: 10, Stop: 20},
+		{Chr: "1", Start: 20, Stop: 30},
+		{Chr: "2", Start: 10, Stop: 20},
+	}
+	type testCase struct {
+		testing       string
+		chr           string
+		start         int
+		stop          int
+		expectedLines []Line
+		expectedIdxs  []int
+	}
+	testCases := []testCase{
+		{
+			testing: "overlapping several, sorted by start",
+			chr:     "1", start: 15, stop: 25,
+			expectedLines: []Line{
+				{Chr: "1", Start: 0, Stop: 1000},
+				{Chr: "1", Start: 10, Stop: 20},
+				{Chr: "1", Start: 20, Stop: 30},
+			},
+			expectedIdxs: []int{1, 2, 3},
+		},
+		{
+			testing: "touching regions are not overlapping",
+			chr:     "1", start: 30, stop: 50,
+			expectedLines: []Line{
+				{Chr: "1", Start: 0, Stop: 1000},
+			},
+			expectedIdxs: []int{1},
+		},
+		{
+			testing: "long region found far away",
+			chr:     "1", start: 900, stop: 950,
+			expectedLines: []Line{
+				{Chr: "1", Start: 0, Stop: 1000},
+			},
+			expectedIdxs: []int{1},
+		},
+		{
+			testing: "other chromosome",
+			chr:     "2", start: 0, stop: 11,
+			expectedLines: []Line{
+				{Chr: "2", Start: 10, Stop: 20},
+			},
+			expectedIdxs: []int{4},
+		},
+		{
+			testing: "no overlap",
+			chr:     "2", start: 20, stop: 30,
+		},
+		{
+			testing: "unknown chromosome",
+			chr:     "3", start: 0, stop: 100,
+		},
+	}
+	index := newRegionIndex(lines)
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			overlapping := index.overlapping(tc.chr, tc.start, tc.stop)
+			if diff := deep.Equal(tc.expectedLines, overlapping); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+			idxs := index.overlappingIdxs(tc.chr, tc.start, tc.stop)
+			if diff := deep.Equal(tc.expectedIdxs, idxs); diff != nil {
+				t.Error("expected VS received positions", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package bed
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// Intersect modes
+var OverlapIM = "overlap"      // report the overlapping part of the regions
+var OriginalIM = "original"    // report the original regions that overlap (like bedtools intersect -u)
+var NoOverlapIM = "no-overlap" // report the regions that do not overlap (like bedtools intersect -v)
+var BothIM = "both"            // report the original regions together with the regions they overlap (like bedtools intersect -wa -wb)
+
+type Intersect struct {
+	B    []string `short:"b" required:"" help:"Bed file path(s) to compare the inputs against. If more than one is provided the files will be joined as if they were one file. If --strand-col is set these files must have the strand in the same column"`
+	Mode string   `env:"INTERSECT_MODE" group:"intersect" enum:"${overlapIM},${originalIM},${noOverlapIM},${bothIM}" default:"${overlapIM}" help:"What to report. ${overlapIM} = the overlapping part of each pair of overlapping regions, ${originalIM} = the input regions that overlap at least one region, ${noOverlapIM} = the input regions that do not overlap any region, ${bothIM} = each pair of overlapping regions with the columns of the input region followed by the columns of the region it overlaps"`
+}
+
+// Verifies and handles Intersect input
+func (is *Intersect) VerifyAndHandle() error {
+	for i, b := range is.B {
+		is.B[i] = filepath.Clean(b)
+	}
+	return nil
+}
+
+// Intersect lines with the regions in the --b bed files
+func (bf *Bedfile) Intersect(is Intersect) error {
+	other, err := bf.readOther(is.B)
+	if err != nil {
+		return err
+	}
+	bf.Lines, err = bf.intersectLines(other.Lines, is.Mode)
+	return err
+}
+
+// Reading bed files the inputs are compared against
+//
+// The files are read with the same strand column and
+// chromosome aliases as the inputs
+func (bf *Bedfile) readOther(inputs []string) (Bedfile, error) {
+	other := Bedfile{
+		Inputs:      inputs,
+		StrandCol:   bf.StrandCol,
+		ChrAlias:    bf.ChrAlias,
+		ChrAliasSet: bf.ChrAliasSet,
+		Schema:      bf.Schema,
+		SchemaFill:  bf.SchemaFill,
+		chrAliases:  bf.chrAliases,
+		colMaps:     bf.colMaps,
+		warnings:    bf.warnings,
+	}
+	err := other.Read()
+	return other, err
+}
+
+// Intersect lines with other lines according to the intersect mode
+//
+// Regions are overlapping if they are on the same chromosome, share
+// at least one base and, if --strand-col is set, are on the same strand
+func (bf *Bedfile) intersectLines(others []Line, mode string) ([]Line, error) {
+	if !stringInSlice([]string{OverlapIM, OriginalIM, NoOverlapIM, BothIM}, mode) {
+		return nil, fmt.Errorf("unknown intersect mode %s", mode)
+	}
+	index := newRegionIndex(others)
+	var intersected []Line
+	for _, l := range bf.Lines {
+		overlapping := slices.DeleteFunc(index.overlapping(l.Chr, l.Start, l.Stop), func(o Line) bool {
+			return o.Strand != l.Strand
+		})
+		switch mode {
+		case OverlapIM:
+			for _, o := range overlapping {
+				intersected = append(intersected, overlapPart(l, o))
+			}
+		case OriginalIM:
+			if len(overlapping) > 0 {
+				intersected = append(intersected, l)
+			}
+		case NoOverlapIM:
+			if len(overlapping) == 0 {
+				intersected = append(intersected, l)
+			}
+		case BothIM:
+			for _, o := range overlapping {
+				both := l
+				both.setOptCols(slices.Concat(l.optCols(), o.Full()))
+				intersected = append(intersected, both)
+			}
+		}
+	}
+	return intersected, nil
+}
+
+// Returns the part of the line overlapping the other line
+func overlapPart(l, other Line) Line {
+	return linePart(l, max(l.Start, other.Start), min(l.Stop, other.Stop))
+}
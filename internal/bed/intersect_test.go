@@ -0,0 +1,179 @@
+package bed
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+var testIntersectA = []Line{
+	{
+		Chr: "1", Start: 1, Stop: 10,
+		Strand: "+",
+		Opt:    []byte("+\tA"),
+	},
+	{
+		Chr: "1", Start: 20, Stop: 30,
+		Strand: "-",
+		Opt:    []byte("-\tB"),
+	},
+	{
+		Chr: "2", Start: 5, Stop: 8,
+		Strand: "+",
+		Opt:    []byte("+\tC"),
+	},
+}
+
+var testIntersectB = []Line{
+	{
+		Chr: "1", Start: 5, Stop: 25,
+		Strand: "+",
+		Opt:    []byte("+"),
+	},
+	{
+		Chr: "1", Start: 8, Stop: 12,
+		Strand: "-",
+		Opt:    []byte("-"),
+	},
+	{
+		Chr: "2", Start: 8, Stop: 10,
+		Strand: "+",
+		Opt:    []byte("+"),
+	},
+}
+
+// Remove strand from lines to test intersecting without --strand-col
+func withoutStrand(lines []Line) []Line {
+	lines = deepCopyLines(lines)
+	for i := range lines {
+		lines[i].Strand = ""
+	}
+	return lines
+}
+
+func TestIntersectLines(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		lines         []Line
+		others        []Line
+		mode          string
+		expectedLines []Line
+		shouldFail    bool
+	}
+	testCases := []testCase{
+		{
+			testing: "overlap",
+			lines:   withoutStrand(testIntersectA),
+			others:  withoutStrand(testIntersectB),
+			mode:    OverlapIM,
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 5, Stop: 10,
+					Opt: []byte("+\tA"),
+				},
+				{
+					Chr: "1", Start: 8, Stop: 10,
+					Opt: []byte("+\tA"),
+				},
+				{
+					Chr: "1", Start: 20, Stop: 25,
+					Opt: []byte("-\tB"),
+				},
+			},
+		},
+		{
+			testing: "overlap with strand",
+			lines:   deepCopyLines(testIntersectA),
+			others:  deepCopyLines(testIntersectB),
+			mode:    OverlapIM,
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 5, Stop: 10,
+					Strand: "+",
+					Opt:    []byte("+\tA"),
+				},
+			},
+		},
+		{
+			testing: "original",
+			lines:   withoutStrand(testIntersectA),
+			others:  withoutStrand(testIntersectB),
+			mode:    OriginalIM,
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 1, Stop: 10,
+					Opt: []byte("+\tA"),
+				},
+				{
+					Chr: "1", Start: 20, Stop: 30,
+					Opt: []byte("-\tB"),
+				},
+			},
+		},
+		{
+			testing: "no overlap, touching regions are not overlapping",
+			lines:   withoutStrand(testIntersectA),
+			others:  withoutStrand(testIntersectB),
+			mode:    NoOverlapIM,
+			expectedLines: []Line{
+				{
+					Chr: "2", Start: 5, Stop: 8,
+					Opt: []byte("+\tC"),
+				},
+			},
+		},
+		{
+			testing: "no overlap with strand",
+			lines:   deepCopyLines(testIntersectA),
+			others:  deepCopyLines(testIntersectB),
+			mode:    NoOverlapIM,
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 20, Stop: 30,
+					Strand: "-",
+					Opt:    []byte("-\tB"),
+				},
+				{
+					Chr: "2", Start: 5, Stop: 8,
+					Strand: "+",
+					Opt:    []byte("+\tC"),
+				},
+			},
+		},
+		{
+			testing: "both",
+			lines:   deepCopyLines(testIntersectA),
+			others:  deepCopyLines(testIntersectB),
+			mode:    BothIM,
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 1, Stop: 10,
+					Strand: "+",
+					Opt:    []byte("+\tA\t1\t5\t25\t+"),
+				},
+			},
+		},
+		{
+			testing:    "unknown mode",
+			lines:      deepCopyLines(testIntersectA),
+			others:     deepCopyLines(testIntersectB),
+			mode:       "unknown",
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			bf := Bedfile{Lines: tc.lines}
+			lines, err := bf.intersectLines(tc.others, tc.mode)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if diff := deep.Equal(tc.expectedLines, lines); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package bed
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Index of the first optional column, the column after stop
+const firstOptIdx = stopIdx + 1
+
+// Returns all columns of the line, with the chr,
+// start and stop columns made from the fields of the line
+func (l Line) Full() []string {
+	full := make([]string, firstOptIdx, firstOptIdx+1+bytes.Count(l.Opt, []byte("\t")))
+	full[chrIdx] = l.Chr
+	full[startIdx] = strconv.Itoa(l.Start)
+	full[stopIdx] = strconv.Itoa(l.Stop)
+	return append(full, l.optCols()...)
+}
+
+// Number of columns of the line
+func (l Line) nrOfCols() int {
+	if l.Opt == nil {
+		return firstOptIdx
+	}
+	return firstOptIdx + 1 + bytes.Count(l.Opt, []byte("\t"))
+}
+
+// Returns the optional columns, the columns after stop
+func (l Line) optCols() []string {
+	if l.Opt == nil {
+		return nil
+	}
+	return strings.Split(string(l.Opt), "\t")
+}
+
+// Replace the optional columns, the chr, start and
+// stop columns are always made from the fields of the line
+//
+// A new slice is always allocated, so that the optional
+// columns can be shared between copies of a line
+func (l *Line) setOptCols(cols []string) {
+	if len(cols) == 0 {
+		l.Opt = nil
+		return
+	}
+	l.Opt = []byte(strings.Join(cols, "\t"))
+}
+
+// Returns the column with the 0-based index idx, and
+// false if the line does not have that many columns
+func (l Line) col(idx int) (string, bool) {
+	switch idx {
+	case chrIdx:
+		return l.Chr, true
+	case startIdx:
+		return strconv.Itoa(l.Start), true
+	case stopIdx:
+		return strconv.Itoa(l.Stop), true
+	}
+	col, ok := nthField(l.Opt, idx-firstOptIdx)
+	return string(col), ok && l.Opt != nil
+}
+
+// Returns the line as tab separated text
+func (l Line) text() string {
+	return string(l.appendText(nil))
+}
+
+// Append the line as tab separated text to b
+func (l Line) appendText(b []byte) []byte {
+	b = append(b, l.Chr...)
+	b = append(b, '\t')
+	b = strconv.AppendInt(b, int64(l.Start), 10)
+	b = append(b, '\t')
+	b = strconv.AppendInt(b, int64(l.Stop), 10)
+	if l.Opt != nil {
+		b = append(b, '\t')
+		b = append(b, l.Opt...)
+	}
+	return b
+}
+
+// Returns the n-th (0-based) tab separated field of text
+// without splitting it, and false if there are too few fields
+func nthField(text []byte, n int) ([]byte, bool) {
+	if n < 0 {
+		return nil, false
+	}
+	for ; n > 0; n-- {
+		idx := bytes.IndexByte(text, '\t')
+		if idx < 0 {
+			return nil, false
+		}
+		text = text[idx+1:]
+	}
+	if idx := bytes.IndexByte(text, '\t'); idx >= 0 {
+		return text[:idx], true
+	}
+	return text, true
+}
+
+// Returns the string with the same content as b, so that equal
+// chromosomes, strands and features share the same string instead
+// of allocating a new string for every line
+func (bf *Bedfile) intern(b []byte) string {
+	if s, ok := bf.strs[string(b)]; ok {
+		return s
+	}
+	if bf.strs == nil {
+		bf.strs = map[string]string{}
+	}
+	s := string(b)
+	bf.strs[s] = s
+	return s
+}
@@ -0,0 +1,215 @@
+package bed
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/go-test/deep"
+)
+
+func TestLineColumns(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing          string
+		line             Line
+		expectedFull     []string
+		expectedNrOfCols int
+		expectedText     string
+	}
+	testCases := []testCase{
+		{
+			testing:          "line without optional columns",
+			line:             Line{Chr: "1", Start: 1, Stop: 4},
+			expectedFull:     []string{"1", "1", "4"},
+			expectedNrOfCols: 3,
+			expectedText:     "1\t1\t4",
+		},
+		{
+			testing:          "line with optional columns",
+			line:             Line{Chr: "1", Start: 1, Stop: 4, Opt: []byte("+\tA")},
+			expectedFull:     []string{"1", "1", "4", "+", "A"},
+			expectedNrOfCols: 5,
+			expectedText:     "1\t1\t4\t+\tA",
+		},
+		{
+			testing:          "line with one empty optional column",
+			line:             Line{Chr: "1", Start: 1, Stop: 4, Opt: []byte{}},
+			expectedFull:     []string{"1", "1", "4", ""},
+			expectedNrOfCols: 4,
+			expectedText:     "1\t1\t4\t",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			if diff := deep.Equal(tc.expectedFull, tc.line.Full()); diff != nil {
+				t.Error("expected VS received columns", diff)
+			}
+			if diff := deep.Equal(tc.expectedNrOfCols, tc.line.nrOfCols()); diff != nil {
+				t.Error("expected VS received number of columns", diff)
+			}
+			if diff := deep.Equal(tc.expectedText, tc.line.text()); diff != nil {
+				t.Error("expected VS received text", diff)
+			}
+			for idx, expectedCol := range tc.expectedFull {
+				col, ok := tc.line.col(idx)
+				if !ok || col != expectedCol {
+					t.Errorf("expected column %d to be %q, got %q (%t)", idx, expectedCol, col, ok)
+				}
+			}
+			if _, ok := tc.line.col(len(tc.expectedFull)); ok {
+				t.Errorf("expected no column %d", len(tc.expectedFull))
+			}
+		})
+	}
+}
+
+func TestSetOptCols(t *testing.T) {
+	t.Parallel()
+	opt := []byte("+\tA")
+	l := Line{Chr: "1", Start: 1, Stop: 4, Opt: opt}
+	copied := l
+	l.setOptCols([]string{"-", "B", "C"})
+	if diff := deep.Equal([]byte("-\tB\tC"), l.Opt); diff != nil {
+		t.Error("expected VS received optional columns", diff)
+	}
+	// The optional columns of copies must not be changed
+	if diff := deep.Equal([]byte("+\tA"), copied.Opt); diff != nil {
+		t.Error("expected VS received optional columns of copy", diff)
+	}
+	l.setOptCols(nil)
+	if l.Opt != nil {
+		t.Errorf("expected no optional columns, got %q", l.Opt)
+	}
+}
+
+func TestIntern(t *testing.T) {
+	t.Parallel()
+	var bf Bedfile
+	a := bf.intern([]byte("chr1"))
+	b := bf.intern([]byte("chr1"))
+	if a != "chr1" || unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Errorf("expected equal strings to share memory: %q and %q", a, b)
+	}
+	if diff := deep.Equal(map[string]string{"chr1": "chr1"}, bf.strs); diff != nil {
+		t.Error("expected VS received interned strings", diff)
+	}
+}
+
+// --- Benchmarks ---
+
+// Bed file with nrOfLines lines on 24 chromosomes, with strand and
+// gene name columns, where every fourth line overlaps the next
+// line on the same strand
+func benchmarkBed(nrOfLines int) []byte {
+	var buf bytes.Buffer
+	for i := range nrOfLines {
+		chr := fmt.Sprintf("chr%d", i*24/nrOfLines+1)
+		start := i * 100
+		stop := start + 50
+		if i%4 == 0 {
+			stop += 200
+		}
+		strand := []string{"+", "-"}[i%2]
+		fmt.Fprintf(&buf, "%s\t%d\t%d\t%s\tgene%d\n", chr, start, stop, strand, i%20_000)
+	}
+	return buf.Bytes()
+}
+
+// Report the heap memory per line kept alive by keep
+func reportHeapPerLine(b *testing.B, nrOfLines int, keep func() any) {
+	b.Helper()
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	kept := keep()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(kept)
+	b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(nrOfLines), "heap-B/line")
+}
+
+// Reading one million lines with the compact representation of lines
+func BenchmarkReadBed(b *testing.B) {
+	const nrOfLines = 1_000_000
+	content := benchmarkBed(nrOfLines)
+	read := func() any {
+		bf := Bedfile{StrandCol: 3}
+		if err := bf.readBed(bytes.NewReader(content)); err != nil {
+			b.Fatal(err)
+		}
+		return bf.Lines
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		read()
+	}
+	b.StopTimer()
+	reportHeapPerLine(b, nrOfLines, read)
+}
+
+// Reading one million lines where every line keeps all of its columns
+// as separate strings, as lines were kept before, for comparison with
+// BenchmarkReadBed
+func BenchmarkReadBedAsColumns(b *testing.B) {
+	const nrOfLines = 1_000_000
+	content := benchmarkBed(nrOfLines)
+	type columnLine struct {
+		Chr      string
+		Start    int
+		Stop     int
+		Strand   string
+		Feat     string
+		Full     []string
+		Comments []string
+	}
+	read := func() any {
+		var lines []columnLine
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			l := columnLine{Full: strings.Split(scanner.Text(), "\t")}
+			l.Chr = l.Full[chrIdx]
+			l.Start, _ = strconv.Atoi(l.Full[startIdx])
+			l.Stop, _ = strconv.Atoi(l.Full[stopIdx])
+			l.Strand = l.Full[3]
+			lines = append(lines, l)
+		}
+		return lines
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		read()
+	}
+	b.StopTimer()
+	reportHeapPerLine(b, nrOfLines, read)
+}
+
+// Merging and sorting one million lines
+func BenchmarkMergeAndSort(b *testing.B) {
+	const nrOfLines = 1_000_000
+	content := benchmarkBed(nrOfLines)
+	b.ReportAllocs()
+	for range b.N {
+		b.StopTimer()
+		bf := Bedfile{StrandCol: 3, SortType: NatST}
+		if err := bf.readBed(bytes.NewReader(content)); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if err := bf.MergeAndPadLines(); err != nil {
+			b.Fatal(err)
+		}
+		if err := bf.Sort(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package bed
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Problem severities
+var WarningSV = "warning" // the file can be used, but the problem might give unexpected results
+var ErrorSV = "error"     // the file is invalid or can not be used by bedfusion
+
+// Lint output formats
+var TextLF = "text" // one line per problem
+var JSONLF = "json" // JSON object with all problems and the number of problems per severity
+
+type Lint struct {
+	LintFormat string `env:"LINT_FORMAT" group:"lint" enum:"${textLF},${jsonLF}" default:"${textLF}" help:"Format of the report. ${textLF} = one line per problem (<file>:<line>:<column>: <severity>: <message>), ${jsonLF} = JSON object containing all problems and the number of problems per severity"`
+}
+
+// Problem found while linting, line and column are
+// 1-based and 0 if the problem is not on a single line or column
+type Problem struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Report of all problems found while linting
+type LintReport struct {
+	Problems []Problem `json:"problems"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+}
+
+// Check the input bed files and report every problem that is found
+//
+// Unlike reading, linting continues after a problem so that all
+// problems in the files can be reported at once. Files that can not
+// be opened or read are reported as problems as well.
+func (bf *Bedfile) Lint() (LintReport, error) {
+	var report LintReport
+	if err := bf.verifyNotBedpe(); err != nil {
+		return report, err
+	}
+	if err := bf.readChrAliasFile(); err != nil {
+		return report, err
+	}
+	if err := bf.readFastaIdxFile(); err != nil {
+		report.add(Problem{File: bf.FastaIdx, Severity: ErrorSV, Message: err.Error()})
+	}
+	chrCompare, err := bf.chrCompareFunc()
+	if err != nil {
+		return report, err
+	}
+	var expectedNrOfCols int
+	for _, input := range bf.Inputs {
+		bedFile, err := openInput(input)
+		if err != nil {
+			report.add(Problem{File: input, Severity: ErrorSV, Message: err.Error()})
+			continue
+		}
+		bf.currentInput = input
+		bf.lintBed(bedFile, input, &expectedNrOfCols, chrCompare, &report)
+		bedFile.Close()
+	}
+	return report, nil
+}
+
+// Check a single bed file, expectedNrOfCols is shared
+// between the files as they are joined when read
+func (bf *Bedfile) lintBed(file io.Reader, name string, expectedNrOfCols *int, chrCompare func(a, b string) int, report *LintReport) {
+	minNrCols := 3
+
+	problem := func(lineNr, col int, severity, format string, a ...any) {
+		report.add(Problem{
+			File: name, Line: lineNr, Column: col,
+			Severity: severity, Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	var prev Line
+	hasPrev := false
+	sorted := true
+	regionsRead := false
+	lineNr := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNr++
+		lineText := scanner.Text()
+
+		// Convert lines of other input formats to bed lines
+		if bf.InputFormat == GFF3IF && lineText == "##FASTA" {
+			break
+		}
+		line, keep, err := bf.toBedLine([]byte(lineText), lineNr)
+		lineText = string(line)
+		if err != nil {
+			problem(lineNr, 0, ErrorSV, "%v", err)
+			continue
+		}
+		if !keep {
+			continue
+		}
+
+		// Comments are allowed anywhere, while browser and track
+		// lines after the first region are moved to the header
+		if headerPattern.MatchString(lineText) {
+			if regionsRead && !commentPattern.MatchString(lineText) {
+				problem(lineNr, 0, WarningSV, "header line after the first region, it is handled according to --header-policy: %s", lineText)
+			}
+			continue
+		}
+		regionsRead = true
+
+		cols := bf.project(strings.Split(lineText, "\t"))
+		if len(cols) < minNrCols {
+			problem(lineNr, 0, ErrorSV, "less than %d columns: %s", minNrCols, lineText)
+			continue
+		}
+		if *expectedNrOfCols == 0 {
+			*expectedNrOfCols = len(cols)
+		} else if len(cols) != *expectedNrOfCols {
+			problem(lineNr, 0, ErrorSV, "expected %d columns got %d", *expectedNrOfCols, len(cols))
+		}
+
+		// Coordinates
+		l := Line{Chr: bf.resolveChr(cols[chrIdx])}
+		var startErr, stopErr error
+		l.Start, startErr = strconv.Atoi(cols[startIdx])
+		if startErr != nil {
+			problem(lineNr, startIdx+1, ErrorSV, "non-int start position: %s", cols[startIdx])
+		} else if l.Start < 0 {
+			problem(lineNr, startIdx+1, ErrorSV, "negative start position: %d", l.Start)
+		}
+		l.Stop, stopErr = strconv.Atoi(cols[stopIdx])
+		if stopErr != nil {
+			problem(lineNr, stopIdx+1, ErrorSV, "non-int stop position: %s", cols[stopIdx])
+		}
+		coordsOK := startErr == nil && stopErr == nil
+		if coordsOK {
+			if l.Start > l.Stop {
+				problem(lineNr, startIdx+1, ErrorSV, "start is greater than stop: %d > %d", l.Start, l.Stop)
+			} else if l.Start == l.Stop {
+				problem(lineNr, startIdx+1, WarningSV, "zero-length region, start and stop is equal: %d == %d", l.Start, l.Stop)
+			}
+		}
+
+		// Strand
+		if bf.StrandCol > stopIdx {
+			if bf.StrandCol > len(cols)-1 {
+				problem(lineNr, bf.StrandCol+1, ErrorSV, "given strand column, %d, is outside bed file (nr columns=%d)", bf.StrandCol+1, len(cols))
+			} else if !strandPattern.MatchString(cols[bf.StrandCol]) {
+				problem(lineNr, bf.StrandCol+1, ErrorSV, "unexpected strand format: %s", cols[bf.StrandCol])
+			}
+		}
+
+		// Blocks
+		if bf.Bed12 && coordsOK {
+			b12 := Line{Start: l.Start, Stop: l.Stop}
+			b12.setOptCols(cols[firstOptIdx:])
+			if err := verifyBed12Line(b12, lineNr); err != nil {
+				problem(lineNr, 0, ErrorSV, "%v", err)
+			}
+		}
+
+		// Chromosome borders
+		if bf.chrLengthMap != nil {
+			chrLength, ok := bf.chrLengthMap[l.Chr]
+			if !ok {
+				problem(lineNr, chrIdx+1, WarningSV, "chromosome %s is not in %s", l.Chr, bf.chrLengthsName())
+			} else if stopErr == nil && l.Stop > chrLength {
+				problem(lineNr, stopIdx+1, ErrorSV, "stop is past the end of chromosome %s: %d > %d", l.Chr, l.Stop, chrLength)
+			}
+		}
+
+		// Sorting, only the first unsorted line of each file is reported
+		if !coordsOK {
+			continue
+		}
+		if sorted && hasPrev && cmp.Or(chrCompare(prev.Chr, l.Chr), cmp.Compare(prev.Start, l.Start)) > 0 {
+			problem(lineNr, 0, WarningSV, "file is not sorted according to --sort-type=%s, first unsorted line: %s", bf.SortType, lineText)
+			sorted = false
+		}
+		prev = l
+		hasPrev = true
+	}
+	if err := scanner.Err(); err != nil {
+		problem(lineNr, 0, ErrorSV, "can't read bed file: %v", err)
+	}
+}
+
+// Add problem to the report
+func (r *LintReport) add(p Problem) {
+	r.Problems = append(r.Problems, p)
+	switch p.Severity {
+	case ErrorSV:
+		r.Errors++
+	case WarningSV:
+		r.Warnings++
+	}
+}
+
+// Exit code reflecting the worst severity found:
+// 0 = no problems, 3 = warnings, 4 = errors
+//
+// 1 and 2 are left out, as they are used when
+// the command fails or is used incorrectly
+func (r LintReport) ExitCode() int {
+	switch {
+	case r.Errors > 0:
+		return 4
+	case r.Warnings > 0:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Write report in the given format
+func (r LintReport) Write(writer io.Writer, format string) error {
+	switch format {
+	case TextLF:
+		return r.writeText(writer)
+	case JSONLF:
+		return r.writeJSON(writer)
+	default:
+		return fmt.Errorf("unknown lint format %s", format)
+	}
+}
+
+// Write one line per problem followed by a summary
+func (r LintReport) writeText(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+	for _, p := range r.Problems {
+		location := p.File
+		if p.Line != 0 {
+			location = fmt.Sprintf("%s:%d", location, p.Line)
+		}
+		if p.Column != 0 {
+			location = fmt.Sprintf("%s:%d", location, p.Column)
+		}
+		fmt.Fprintf(w, "%s: %s: %s\n", location, p.Severity, p.Message)
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", r.Errors, r.Warnings)
+	return w.Flush()
+}
+
+// Write the report as a JSON object
+func (r LintReport) writeJSON(writer io.Writer) error {
+	if r.Problems == nil {
+		r.Problems = []Problem{}
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Write report to the output file or standard output
+func (bf *Bedfile) WriteLintReport(report LintReport, format string) error {
+	return bf.openOutput(func(writer io.Writer) error {
+		return report.Write(writer, format)
+	})
+}
@@ -0,0 +1,176 @@
+package bed
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestLintBed(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing          string
+		bf               Bedfile
+		bedFileContent   string
+		expectedProblems []Problem
+	}
+	testCases := []testCase{
+		{
+			testing:          "valid bed file",
+			bf:               Bedfile{SortType: LexST},
+			bedFileContent:   "browser position chr7:127471196-127495720\n1\t1\t2\n1\t3\t4\n2\t1\t2",
+			expectedProblems: nil,
+		},
+		{
+			testing:        "all problems on a line are reported",
+			bf:             Bedfile{SortType: LexST, StrandCol: 3},
+			bedFileContent: "1\tx\t-1\tq",
+			expectedProblems: []Problem{
+				{File: "test.bed", Line: 1, Column: 2, Severity: ErrorSV, Message: "non-int start position: x"},
+				{File: "test.bed", Line: 1, Column: 4, Severity: ErrorSV, Message: "unexpected strand format: q"},
+			},
+		},
+		{
+			testing:        "problems on several lines are reported",
+			bf:             Bedfile{SortType: LexST},
+			bedFileContent: "1\t5\t3\n1\t-1\t6\n1\t7\t7\n1\t8\n1\t8\t9\tA\n#comment\ntrack name=late",
+			expectedProblems: []Problem{
+				{File: "test.bed", Line: 1, Column: 2, Severity: ErrorSV, Message: "start is greater than stop: 5 > 3"},
+				{File: "test.bed", Line: 2, Column: 2, Severity: ErrorSV, Message: "negative start position: -1"},
+				{File: "test.bed", Line: 2, Column: 0, Severity: WarningSV, Message: "file is not sorted according to --sort-type=lex, first unsorted line: 1\t-1\t6"},
+				{File: "test.bed", Line: 3, Column: 2, Severity: WarningSV, Message: "zero-length region, start and stop is equal: 7 == 7"},
+				{File: "test.bed", Line: 4, Column: 0, Severity: ErrorSV, Message: "less than 3 columns: 1\t8"},
+				{File: "test.bed", Line: 5, Column: 0, Severity: ErrorSV, Message: "expected 3 columns got 4"},
+				{File: "test.bed", Line: 7, Column: 0, Severity: WarningSV, Message: "header line after the first region, it is handled according to --header-policy: track name=late"},
+			},
+		},
+		{
+			testing:        "strand column outside bed file",
+			bf:             Bedfile{SortType: LexST, StrandCol: 4},
+			bedFileContent: "1\t1\t2\t+",
+			expectedProblems: []Problem{
+				{File: "test.bed", Line: 1, Column: 5, Severity: ErrorSV, Message: "given strand column, 5, is outside bed file (nr columns=4)"},
+			},
+		},
+		{
+			testing:        "chromosome borders",
+			bf:             Bedfile{SortType: LexST, FastaIdx: "test.fasta.fai", chrLengthMap: testChrLengthMap},
+			bedFileContent: "1\t1\t100\n1\t1\t101\n5\t1\t2",
+			expectedProblems: []Problem{
+				{File: "test.bed", Line: 2, Column: 3, Severity: ErrorSV, Message: "stop is past the end of chromosome 1: 101 > 100"},
+				{File: "test.bed", Line: 3, Column: 1, Severity: WarningSV, Message: "chromosome 5 is not in fasta index file test.fasta.fai"},
+			},
+		},
+		{
+			testing:        "only the first unsorted line is reported",
+			bf:             Bedfile{SortType: NatST},
+			bedFileContent: "10\t1\t2\n2\t1\t2\n1\t1\t2",
+			expectedProblems: []Problem{
+				{File: "test.bed", Line: 2, Column: 0, Severity: WarningSV, Message: "file is not sorted according to --sort-type=nat, first unsorted line: 2\t1\t2"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var report LintReport
+			var expectedNrOfCols int
+			chrCompare, err := tc.bf.chrCompareFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.bf.lintBed(strings.NewReader(tc.bedFileContent), "test.bed", &expectedNrOfCols, chrCompare, &report)
+			if diff := deep.Equal(tc.expectedProblems, report.Problems); diff != nil {
+				t.Error("expected VS received problems", diff)
+			}
+		})
+	}
+}
+
+func TestLintReport(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing          string
+		problems         []Problem
+		format           string
+		expectedOutput   string
+		expectedExitCode int
+		shouldFail       bool
+	}
+	testProblems := []Problem{
+		{File: "a.bed", Line: 2, Column: 3, Severity: ErrorSV, Message: "error message"},
+		{File: "a.bed", Line: 4, Severity: WarningSV, Message: "warning message"},
+		{File: "b.bed", Severity: ErrorSV, Message: "file message"},
+	}
+	testCases := []testCase{
+		{
+			testing:          "no problems as text",
+			format:           TextLF,
+			expectedOutput:   "0 error(s), 0 warning(s)\n",
+			expectedExitCode: 0,
+		},
+		{
+			testing:  "only warnings as text",
+			problems: testProblems[1:2],
+			format:   TextLF,
+			expectedOutput: "a.bed:4: warning: warning message\n" +
+				"0 error(s), 1 warning(s)\n",
+			expectedExitCode: 3,
+		},
+		{
+			testing:  "errors and warnings as text",
+			problems: testProblems,
+			format:   TextLF,
+			expectedOutput: "a.bed:2:3: error: error message\n" +
+				"a.bed:4: warning: warning message\n" +
+				"b.bed: error: file message\n" +
+				"2 error(s), 1 warning(s)\n",
+			expectedExitCode: 4,
+		},
+		{
+			testing:          "no problems as json",
+			format:           JSONLF,
+			expectedOutput:   "{\n  \"problems\": [],\n  \"errors\": 0,\n  \"warnings\": 0\n}\n",
+			expectedExitCode: 0,
+		},
+		{
+			testing:  "errors as json",
+			problems: testProblems[:1],
+			format:   JSONLF,
+			expectedOutput: "{\n  \"problems\": [\n    {\n      \"file\": \"a.bed\",\n      \"line\": 2,\n      \"column\": 3,\n" +
+				"      \"severity\": \"error\",\n      \"message\": \"error message\"\n    }\n  ],\n  \"errors\": 1,\n  \"warnings\": 0\n}\n",
+			expectedExitCode: 4,
+		},
+		{
+			testing:    "unknown format",
+			format:     "xml",
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var report LintReport
+			for _, p := range tc.problems {
+				report.add(p)
+			}
+			var buf bytes.Buffer
+			err := report.Write(&buf, tc.format)
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedOutput, buf.String()); diff != nil {
+					t.Error("expected VS received output", diff)
+				}
+				if diff := deep.Equal(tc.expectedExitCode, report.ExitCode()); diff != nil {
+					t.Error("expected VS received exit code", diff)
+				}
+			}
+		})
+	}
+}
@@ -1,23 +1,68 @@
 package bed
 
 import (
+	"bytes"
 	"fmt"
-	"strconv"
+	"slices"
 	"strings"
 )
 
 // Merge and pad lines in bed file
+//
+// With --threads the lines are partitioned by chromosome,
+// and the partitions are merged and padded concurrently
 func (bf *Bedfile) MergeAndPadLines() error {
+	var partitions []partition
+	if bf.Threads > 1 {
+		partitions = partitionByChr(bf.Lines)
+	}
+	if len(partitions) <= 1 {
+		var chrNotInLengthMap []string
+		var err error
+		bf.Lines, chrNotInLengthMap, err = bf.mergeAndPadLines(bf.Lines)
+		if err != nil {
+			return err
+		}
+		// If we have been padding print padding warnings
+		if bf.HasPadding() {
+			bf.paddingWarnings(chrNotInLengthMap)
+		}
+		return nil
+	}
+	chrNotInLengthMaps := make([][]string, len(partitions))
+	err := bf.forEachPartition(partitions, func(i int) error {
+		var err error
+		partitions[i].lines, chrNotInLengthMaps[i], err = bf.mergeAndPadLines(partitions[i].lines)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// If we have been padding print padding warnings
+	if bf.HasPadding() {
+		bf.paddingWarnings(slices.Concat(chrNotInLengthMaps...))
+	}
+	bf.Lines = concatPartitions(partitions)
+	return nil
+}
+
+// Merge and pad lines, returns the merged lines and
+// the chromosomes that are not in the chromosome length map
+func (bf Bedfile) mergeAndPadLines(lines []Line) ([]Line, []string, error) {
+	if len(lines) == 0 {
+		return nil, nil, nil
+	}
 	var merged Line
+	var values colValues
 	var mergedLines []Line
 	var chrNotInLengthMap []string
-	for i, l := range mergeSort(bf.Lines) {
+	for i, l := range mergeSort(lines) {
 		// Pad line
-		if bf.Padding != 0 {
+		if bf.HasPadding() {
 			var err error
 			l, chrNotInLengthMap, err = bf.padAccordingToPaddingType(l, chrNotInLengthMap)
 			if err != nil {
-				return err
+				return nil, nil, err
 			}
 		}
 
@@ -28,41 +73,66 @@ func (bf *Bedfile) MergeAndPadLines() error {
 			merged.Strand == l.Strand &&
 			merged.Feat == l.Feat &&
 			merged.Stop+bf.Overlap >= l.Start-1 {
-			// Set new stop if it is later than the
-			// merged stop
-			if l.Stop > merged.Stop {
-				merged.Stop = l.Stop
-				merged.Full[stopIdx] = strconv.Itoa(l.Stop)
-			}
-			// Join information in the optional columns
-			if len(l.Full) > stopIdx+1 {
-				for idx, col := range l.Full[stopIdx+1:] {
-					mIdx := idx + stopIdx + 1
-					if !stringInSlice(strings.Split(merged.Full[mIdx], ","), col) {
-						merged.Full[mIdx] = fmt.Sprintf("%s,%s", merged.Full[mIdx], col)
-					}
-				}
-			}
+			bf.mergeLineInto(&merged, values, l)
 		} else {
 			// If we are not on the first line append merged to MergedLines
 			if i != 0 {
+				if err := bf.applyColOps(&merged, values); err != nil {
+					return nil, nil, err
+				}
 				mergedLines = append(mergedLines, merged)
 			}
 			// Create new merged line
 			merged = Line{
 				Chr: l.Chr, Start: l.Start, Stop: l.Stop,
 				Strand: l.Strand, Feat: l.Feat,
-				Full: l.Full,
+				Opt: l.Opt, Comments: l.Comments,
 			}
+			values = bf.newColValues(l)
 		}
 	}
-	// If we have been padding print padding warnings
-	if bf.Padding != 0 {
-		bf.paddingWarnings(chrNotInLengthMap)
+	if err := bf.applyColOps(&merged, values); err != nil {
+		return nil, nil, err
 	}
-	// Replace lines in Bedfile
-	bf.Lines = append(mergedLines, merged)
-	return nil
+	return append(mergedLines, merged), chrNotInLengthMap, nil
+}
+
+// Merge line into an already merged line by extending
+// the stop and joining the optional columns
+//
+// The values of columns with an aggregation operation (see --col-op)
+// are collected in values, and are aggregated by .applyColOps()
+// when the merged line is complete. The blocks of BED12 lines
+// are united (see --bed12)
+func (bf *Bedfile) mergeLineInto(merged *Line, values colValues, l Line) {
+	// Set new stop if it is later than the
+	// merged stop
+	if l.Stop > merged.Stop {
+		merged.Stop = l.Stop
+	}
+	// Join information in the optional columns, there is nothing
+	// to join if they are equal and do not contain any lists
+	if l.Opt != nil && (!bytes.Equal(l.Opt, merged.Opt) || bytes.IndexByte(l.Opt, ',') >= 0) {
+		mergedCols := merged.optCols()
+		for idx, col := range l.optCols() {
+			mIdx := idx + firstOptIdx
+			if _, ok := bf.colOps[mIdx]; ok {
+				continue
+			}
+			if bf.Bed12 && slices.Contains(blockB12Idxs, mIdx) {
+				continue
+			}
+			if !stringInSlice(strings.Split(mergedCols[idx], ","), col) {
+				mergedCols[idx] = fmt.Sprintf("%s,%s", mergedCols[idx], col)
+			}
+		}
+		merged.setOptCols(mergedCols)
+	}
+	if bf.Bed12 {
+		mergeBed12Line(merged, l)
+	}
+	merged.Comments = append(merged.Comments, l.Comments...)
+	values.add(bf.colOps, l)
 }
 
 // Returns true or false depending on if the string
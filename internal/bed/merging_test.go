@@ -10,31 +10,31 @@ import (
 var testMergeChrOnly = []Line{
 	{
 		Chr: "1", Start: 1, Stop: 4,
-		Full: []string{"1", "1", "4", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
-		Full: []string{"1", "5", "8", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 6, Stop: 8,
-		Full: []string{"1", "6", "8", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
-		Full: []string{"1", "5", "8", "-1", "A"},
+		Opt: []byte("-1\tA"),
 	},
 	{
 		Chr: "2", Start: 6, Stop: 8,
-		Full: []string{"2", "6", "8", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
-		Full: []string{"1", "5", "8", "1", "B"},
+		Opt: []byte("1\tB"),
 	},
 	{
 		Chr: "1", Start: 20, Stop: 30,
-		Full: []string{"1", "20", "30", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 }
 
@@ -42,37 +42,37 @@ var testMergeChrStrand = []Line{
 	{
 		Chr: "1", Start: 1, Stop: 4,
 		Strand: "1",
-		Full:   []string{"1", "1", "4", "1", "A"},
+		Opt:    []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Strand: "1",
-		Full:   []string{"1", "5", "8", "1", "A"},
+		Opt:    []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 6, Stop: 8,
 		Strand: "1",
-		Full:   []string{"1", "6", "8", "1", "A"},
+		Opt:    []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Strand: "-1",
-		Full:   []string{"1", "5", "8", "-1", "A"},
+		Opt:    []byte("-1\tA"),
 	},
 	{
 		Chr: "2", Start: 6, Stop: 8,
 		Strand: "1",
-		Full:   []string{"2", "6", "8", "1", "A"},
+		Opt:    []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Strand: "1",
-		Full:   []string{"1", "5", "8", "1", "B"},
+		Opt:    []byte("1\tB"),
 	},
 	{
 		Chr: "1", Start: 20, Stop: 30,
 		Strand: "1",
-		Full:   []string{"1", "20", "30", "1", "A"},
+		Opt:    []byte("1\tA"),
 	},
 }
 
@@ -81,37 +81,37 @@ var testMergeChrFeat = []Line{
 	{
 		Chr: "1", Start: 1, Stop: 4,
 		Feat: "A",
-		Full: []string{"1", "1", "4", "1", "A"},
+		Opt:  []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Feat: "A",
-		Full: []string{"1", "5", "8", "1", "A"},
+		Opt:  []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 6, Stop: 8,
 		Feat: "A",
-		Full: []string{"1", "6", "8", "1", "A"},
+		Opt:  []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Feat: "A",
-		Full: []string{"1", "5", "8", "-1", "A"},
+		Opt:  []byte("-1\tA"),
 	},
 	{
 		Chr: "2", Start: 6, Stop: 8,
 		Feat: "A",
-		Full: []string{"2", "6", "8", "1", "A"},
+		Opt:  []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Feat: "B",
-		Full: []string{"1", "5", "8", "1", "B"},
+		Opt:  []byte("1\tB"),
 	},
 	{
 		Chr: "1", Start: 20, Stop: 30,
 		Feat: "A",
-		Full: []string{"1", "20", "30", "1", "A"},
+		Opt:  []byte("1\tA"),
 	},
 }
 
@@ -119,37 +119,37 @@ var testMergeFull = []Line{
 	{
 		Chr: "1", Start: 1, Stop: 4,
 		Strand: "1", Feat: "A",
-		Full: []string{"1", "1", "4", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Strand: "1", Feat: "A",
-		Full: []string{"1", "5", "8", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 6, Stop: 8,
 		Strand: "1", Feat: "A",
-		Full: []string{"1", "6", "8", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Strand: "-1", Feat: "A",
-		Full: []string{"1", "5", "8", "-1", "A"},
+		Opt: []byte("-1\tA"),
 	},
 	{
 		Chr: "2", Start: 6, Stop: 8,
 		Strand: "1", Feat: "A",
-		Full: []string{"2", "6", "8", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 5, Stop: 8,
 		Strand: "1", Feat: "B",
-		Full: []string{"1", "5", "8", "1", "B"},
+		Opt: []byte("1\tB"),
 	},
 	{
 		Chr: "1", Start: 20, Stop: 30,
 		Strand: "1", Feat: "A",
-		Full: []string{"1", "20", "30", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 }
 
@@ -171,15 +171,15 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 8,
-						Full: []string{"1", "1", "8", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
-						Full: []string{"1", "20", "30", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
-						Full: []string{"2", "6", "8", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -195,19 +195,19 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 4,
-						Full: []string{"1", "1", "4", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "1", Start: 5, Stop: 8,
-						Full: []string{"1", "5", "8", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
-						Full: []string{"1", "20", "30", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
-						Full: []string{"2", "6", "8", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -223,15 +223,15 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 8,
-						Full: []string{"1", "1", "8", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
-						Full: []string{"1", "20", "30", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
-						Full: []string{"2", "6", "8", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -247,11 +247,11 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 30,
-						Full: []string{"1", "1", "30", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
-						Full: []string{"2", "6", "8", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -268,22 +268,22 @@ func TestMergeAndPadLines(t *testing.T) {
 					{
 						Chr: "1", Start: 5, Stop: 8,
 						Strand: "-1",
-						Full:   []string{"1", "5", "8", "-1", "A"},
+						Opt:    []byte("-1\tA"),
 					},
 					{
 						Chr: "1", Start: 1, Stop: 8,
 						Strand: "1",
-						Full:   []string{"1", "1", "8", "1", "A,B"},
+						Opt:    []byte("1\tA,B"),
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
 						Strand: "1",
-						Full:   []string{"1", "20", "30", "1", "A"},
+						Opt:    []byte("1\tA"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
 						Strand: "1",
-						Full:   []string{"2", "6", "8", "1", "A"},
+						Opt:    []byte("1\tA"),
 					},
 				},
 			},
@@ -300,22 +300,22 @@ func TestMergeAndPadLines(t *testing.T) {
 					{
 						Chr: "1", Start: 1, Stop: 8,
 						Feat: "A",
-						Full: []string{"1", "1", "8", "1,-1", "A"},
+						Opt:  []byte("1,-1\tA"),
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
 						Feat: "A",
-						Full: []string{"1", "20", "30", "1", "A"},
+						Opt:  []byte("1\tA"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
 						Feat: "A",
-						Full: []string{"2", "6", "8", "1", "A"},
+						Opt:  []byte("1\tA"),
 					},
 					{
 						Chr: "1", Start: 5, Stop: 8,
 						Feat: "B",
-						Full: []string{"1", "5", "8", "1", "B"},
+						Opt:  []byte("1\tB"),
 					},
 				},
 			},
@@ -334,27 +334,27 @@ func TestMergeAndPadLines(t *testing.T) {
 					{
 						Chr: "1", Start: 5, Stop: 8,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "5", "8", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "1", Start: 1, Stop: 8,
 						Strand: "1", Feat: "A",
-						Full: []string{"1", "1", "8", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
 						Strand: "1", Feat: "A",
-						Full: []string{"1", "20", "30", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
 						Strand: "1", Feat: "A",
-						Full: []string{"2", "6", "8", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "1", Start: 5, Stop: 8,
 						Strand: "1", Feat: "B",
-						Full: []string{"1", "5", "8", "1", "B"},
+						Opt: []byte("1\tB"),
 					},
 				},
 			},
@@ -376,11 +376,11 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 40,
-						Full: []string{"1", "1", "40", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "2", Start: 1, Stop: 18,
-						Full: []string{"2", "1", "18", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -402,11 +402,11 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 40,
-						Full: []string{"1", "1", "40", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "2", Start: 1, Stop: 18,
-						Full: []string{"2", "1", "18", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -428,11 +428,11 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 40,
-						Full: []string{"1", "1", "40", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "2", Start: 1, Stop: 18,
-						Full: []string{"2", "1", "18", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -462,15 +462,15 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 8,
-						Full: []string{"1", "1", "8", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
-						Full: []string{"1", "20", "30", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 					{
 						Chr: "2", Start: 6, Stop: 8,
-						Full: []string{"2", "6", "8", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -490,11 +490,11 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 40,
-						Full: []string{"1", "1", "40", "1,-1", "A,B"},
+						Opt: []byte("1,-1\tA,B"),
 					},
 					{
 						Chr: "2", Start: 1, Stop: 18,
-						Full: []string{"2", "1", "18", "1", "A"},
+						Opt: []byte("1\tA"),
 					},
 				},
 			},
@@ -510,15 +510,12 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 4,
-						Full: []string{"1", "1", "4"},
 					},
 					{
 						Chr: "1", Start: 5, Stop: 9,
-						Full: []string{"1", "5", "9"},
 					},
 					{
 						Chr: "1", Start: 20, Stop: 30,
-						Full: []string{"1", "20", "30"},
 					},
 				},
 			},
@@ -531,15 +528,55 @@ func TestMergeAndPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 14,
-						Full: []string{"1", "1", "14"},
 					},
 					{
 						Chr: "1", Start: 15, Stop: 35,
-						Full: []string{"1", "15", "35"},
 					},
 				},
 			},
 		},
+		{
+			testing: "testMergeChrOnly, column operations",
+			bed: Bedfile{
+				Lines:  deepCopyLines(testMergeChrOnly),
+				colOps: map[int]string{3: "count", 4: "collapse"},
+			},
+			expectedBed: Bedfile{
+				colOps: map[int]string{3: "count", 4: "collapse"},
+				Lines: []Line{
+					{
+						Chr: "1", Start: 1, Stop: 8,
+						Opt: []byte("5\tA,A,A,B,A"),
+					},
+					{
+						Chr: "1", Start: 20, Stop: 30,
+						Opt: []byte("1\tA"),
+					},
+					{
+						Chr: "2", Start: 6, Stop: 8,
+						Opt: []byte("1\tA"),
+					},
+				},
+			},
+		},
+		{
+			testing:     "no lines",
+			bed:         Bedfile{},
+			expectedBed: Bedfile{},
+		},
+		{
+			testing:     "no lines with threads",
+			bed:         Bedfile{Threads: 4},
+			expectedBed: Bedfile{Threads: 4},
+		},
+		{
+			testing: "testMergeChrOnly, column operation on non-numeric column",
+			bed: Bedfile{
+				Lines:  deepCopyLines(testMergeChrOnly),
+				colOps: map[int]string{4: "sum"},
+			},
+			shouldFail: true,
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -2,8 +2,6 @@ package bed
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 )
 
 // Padding types
@@ -42,7 +40,7 @@ func (bf Bedfile) padAccordingToPaddingType(line Line, chrNotInLengthMap []strin
 	if !chrInMap {
 		switch bf.PaddingType {
 		case SafePT:
-			return Line{}, nil, fmt.Errorf("chromosome %s is not in fasta index file %s", line.Chr, bf.FastaIdx)
+			return Line{}, nil, fmt.Errorf("chromosome %s is not in %s", line.Chr, bf.chrLengthsName())
 		case LaxPT:
 			paddedLine = line
 		}
@@ -54,16 +52,16 @@ func (bf Bedfile) padAccordingToPaddingType(line Line, chrNotInLengthMap []strin
 // Handle warnings depending on padding types
 func (bf Bedfile) paddingWarnings(chrNotInLengthMap []string) {
 	if len(chrNotInLengthMap) > 0 {
-		warnMsg := fmt.Sprintf("chromosomes %v not in fasta index file %s",
-			sortAndDeduplicateListOfStrings(chrNotInLengthMap), bf.FastaIdx)
+		warnMsg := fmt.Sprintf("chromosomes %v not in %s",
+			sortAndDeduplicateListOfStrings(chrNotInLengthMap), bf.chrLengthsName())
 		switch bf.PaddingType {
 		case LaxPT:
-			fmt.Fprintf(os.Stderr, "warning: %s, no padding was added to regions on these chromosomes\n", warnMsg)
+			bf.warn("%s, no padding was added to regions on these chromosomes", warnMsg)
 		case ForcePT:
-			if bf.FastaIdx != "" {
-				fmt.Fprintf(os.Stderr, "warning: %s, regions on these chromosomes were still padded\n", warnMsg)
+			if bf.chrLengthMap != nil {
+				bf.warn("%s, regions on these chromosomes were still padded", warnMsg)
 			} else {
-				fmt.Fprintf(os.Stderr, "warning: you are now padding without a fasta index file and might pad regions beyond chromosome borders\n")
+				bf.warn("you are now padding without a fasta index file and might pad regions beyond chromosome borders")
 			}
 		}
 	}
@@ -72,20 +70,20 @@ func (bf Bedfile) paddingWarnings(chrNotInLengthMap []string) {
 // Pad single line
 func (bf Bedfile) padLine(l Line) (Line, bool, error) {
 	var err error
-	// Deep line to make sure we do not overwrite
-	fullLineCopy := make([]string, len(l.Full))
-	_ = copy(fullLineCopy, l.Full)
-	line := Line{
-		Chr: l.Chr, Start: l.Start, Stop: l.Stop,
-		Strand: l.Strand, Feat: l.Feat,
-		Full: fullLineCopy,
-	}
+	// The optional columns are never changed in place,
+	// so they can be shared with the original line
+	line := l
 	// Line
-	line.Start = line.Start - bf.Padding
-	line.Stop = line.Stop + bf.Padding
+	left, right := bf.paddingOf(line)
+	line.Start = line.Start - left
+	line.Stop = line.Stop + right
 	// Make sure we do not end up with a flipped region if negative padding has been used
 	if line.Start >= line.Stop {
-		err = fmt.Errorf("padding with %d will results in start >= stop for: %v", bf.Padding, line.Full)
+		if left == right {
+			err = fmt.Errorf("padding with %d will results in start >= stop for: %v", left, l.Full())
+		} else {
+			err = fmt.Errorf("padding with %d to the left and %d to the right will results in start >= stop for: %v", left, right, l.Full())
+		}
 		return Line{}, false, err
 	}
 	// Make sure that the padding does not exceed the chromosome limits
@@ -96,7 +94,36 @@ func (bf Bedfile) padLine(l Line) (Line, bool, error) {
 	if ok && line.Stop > chrLength {
 		line.Stop = chrLength
 	}
-	line.Full[startIdx] = strconv.Itoa(line.Start)
-	line.Full[stopIdx] = strconv.Itoa(line.Stop)
+	if bf.Bed12 {
+		padBed12Line(&line, l.Start, l.Stop)
+	}
 	return line, ok, err
 }
+
+// Returns true if any kind of padding is selected
+func (bf Bedfile) HasPadding() bool {
+	return bf.Padding != 0 ||
+		bf.PadUpstream != 0 || bf.PadDownstream != 0 ||
+		bf.PadLeft != 0 || bf.PadRight != 0
+}
+
+// Padding to add before the start (left) and after the stop (right) of a line
+//
+// Upstream and downstream padding is flipped for lines on the minus strand
+func (bf Bedfile) paddingOf(l Line) (int, int) {
+	if bf.PadUpstream != 0 || bf.PadDownstream != 0 {
+		if isMinusStrand(l.Strand) {
+			return bf.PadDownstream, bf.PadUpstream
+		}
+		return bf.PadUpstream, bf.PadDownstream
+	}
+	if bf.PadLeft != 0 || bf.PadRight != 0 {
+		return bf.PadLeft, bf.PadRight
+	}
+	return bf.Padding, bf.Padding
+}
+
+// Returns true if the strand is the minus strand (- or -1)
+func isMinusStrand(strand string) bool {
+	return strand == "-" || strand == "-1"
+}
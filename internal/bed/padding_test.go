@@ -9,19 +9,15 @@ import (
 var testLinesToPad = []Line{
 	{
 		Chr: "1", Start: 50, Stop: 51,
-		Full: []string{"1", "50", "51"},
 	},
 	{
 		Chr: "2", Start: 150, Stop: 151,
-		Full: []string{"2", "150", "151"},
 	},
 	{
 		Chr: "3", Start: 250, Stop: 251,
-		Full: []string{"3", "250", "251"},
 	},
 	{
 		Chr: "4", Start: 350, Stop: 351,
-		Full: []string{"4", "350", "351"},
 	},
 }
 
@@ -57,19 +53,15 @@ func TestPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 40, Stop: 61,
-						Full: []string{"1", "40", "61"},
 					},
 					{
 						Chr: "2", Start: 140, Stop: 161,
-						Full: []string{"2", "140", "161"},
 					},
 					{
 						Chr: "3", Start: 240, Stop: 261,
-						Full: []string{"3", "240", "261"},
 					},
 					{
 						Chr: "4", Start: 340, Stop: 361,
-						Full: []string{"4", "340", "361"},
 					},
 				},
 				chrLengthMap: testChrLengthMap,
@@ -91,19 +83,15 @@ func TestPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 40, Stop: 61,
-						Full: []string{"1", "40", "61"},
 					},
 					{
 						Chr: "2", Start: 140, Stop: 161,
-						Full: []string{"2", "140", "161"},
 					},
 					{
 						Chr: "3", Start: 240, Stop: 261,
-						Full: []string{"3", "240", "261"},
 					},
 					{
 						Chr: "4", Start: 340, Stop: 361,
-						Full: []string{"4", "340", "361"},
 					},
 				},
 				chrLengthMap: testChrLengthMap,
@@ -125,19 +113,15 @@ func TestPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 40, Stop: 61,
-						Full: []string{"1", "40", "61"},
 					},
 					{
 						Chr: "2", Start: 140, Stop: 161,
-						Full: []string{"2", "140", "161"},
 					},
 					{
 						Chr: "3", Start: 240, Stop: 261,
-						Full: []string{"3", "240", "261"},
 					},
 					{
 						Chr: "4", Start: 340, Stop: 361,
-						Full: []string{"4", "340", "361"},
 					},
 				},
 				chrLengthMap: testChrLengthMap,
@@ -159,19 +143,15 @@ func TestPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 100,
-						Full: []string{"1", "1", "100"},
 					},
 					{
 						Chr: "2", Start: 1, Stop: 200,
-						Full: []string{"2", "1", "200"},
 					},
 					{
 						Chr: "3", Start: 1, Stop: 300,
-						Full: []string{"3", "1", "300"},
 					},
 					{
 						Chr: "4", Start: 1, Stop: 400,
-						Full: []string{"4", "1", "400"},
 					},
 				},
 				chrLengthMap: testChrLengthMap,
@@ -193,19 +173,15 @@ func TestPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 100,
-						Full: []string{"1", "1", "100"},
 					},
 					{
 						Chr: "2", Start: 1, Stop: 200,
-						Full: []string{"2", "1", "200"},
 					},
 					{
 						Chr: "3", Start: 1, Stop: 300,
-						Full: []string{"3", "1", "300"},
 					},
 					{
 						Chr: "4", Start: 1, Stop: 400,
-						Full: []string{"4", "1", "400"},
 					},
 				},
 				chrLengthMap: testChrLengthMap,
@@ -227,19 +203,15 @@ func TestPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 100,
-						Full: []string{"1", "1", "100"},
 					},
 					{
 						Chr: "2", Start: 1, Stop: 200,
-						Full: []string{"2", "1", "200"},
 					},
 					{
 						Chr: "3", Start: 1, Stop: 300,
-						Full: []string{"3", "1", "300"},
 					},
 					{
 						Chr: "4", Start: 1, Stop: 400,
-						Full: []string{"4", "1", "400"},
 					},
 				},
 				chrLengthMap: testChrLengthMap,
@@ -285,19 +257,15 @@ func TestPadLines(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 1, Stop: 1051,
-						Full: []string{"1", "1", "1051"},
 					},
 					{
 						Chr: "2", Start: 1, Stop: 1151,
-						Full: []string{"2", "1", "1151"},
 					},
 					{
 						Chr: "3", Start: 1, Stop: 1251,
-						Full: []string{"3", "1", "1251"},
 					},
 					{
 						Chr: "4", Start: 1, Stop: 1351,
-						Full: []string{"4", "1", "1351"},
 					},
 				},
 			},
@@ -343,7 +311,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			line: deepCopyLine(testLinesToPad[1]),
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 140, Stop: 161,
-				Full: []string{"2", "140", "161"},
 			},
 		},
 		{
@@ -357,7 +324,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			line: deepCopyLine(testLinesToPad[1]),
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 140, Stop: 161,
-				Full: []string{"2", "140", "161"},
 			},
 		},
 		{
@@ -371,7 +337,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			line: deepCopyLine(testLinesToPad[1]),
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 140, Stop: 161,
-				Full: []string{"2", "140", "161"},
 			},
 		},
 		{
@@ -385,7 +350,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			line: deepCopyLine(testLinesToPad[1]),
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 1, Stop: 200,
-				Full: []string{"2", "1", "200"},
 			},
 		},
 		{
@@ -399,7 +363,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			line: deepCopyLine(testLinesToPad[1]),
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 1, Stop: 200,
-				Full: []string{"2", "1", "200"},
 			},
 		},
 		{
@@ -413,7 +376,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			line: deepCopyLine(testLinesToPad[1]),
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 1, Stop: 200,
-				Full: []string{"2", "1", "200"},
 			},
 		},
 		{
@@ -437,7 +399,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			missChrMap: []string{"1"},
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 150, Stop: 151,
-				Full: []string{"2", "150", "151"},
 			},
 			expectedMisschrMap: []string{"1", "2"},
 		},
@@ -452,7 +413,6 @@ func TestPadLineAccordingToPaddingType(t *testing.T) {
 			missChrMap: []string{"1"},
 			expectedPaddedLine: Line{
 				Chr: "2", Start: 1, Stop: 1151,
-				Full: []string{"2", "1", "1151"},
 			},
 			expectedMisschrMap: []string{"1", "2"},
 		},
@@ -504,11 +464,9 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 50, Stop: 51,
-				Full: []string{"1", "50", "51"},
 			},
 			expectedLine: Line{
 				Chr: "1", Start: 40, Stop: 61,
-				Full: []string{"1", "40", "61"},
 			},
 			expectedChrInMap: true,
 		},
@@ -523,11 +481,9 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 50, Stop: 51,
-				Full: []string{"1", "50", "51"},
 			},
 			expectedLine: Line{
 				Chr: "1", Start: 40, Stop: 61,
-				Full: []string{"1", "40", "61"},
 			},
 			expectedChrInMap: true,
 		},
@@ -542,11 +498,9 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 50, Stop: 51,
-				Full: []string{"1", "50", "51"},
 			},
 			expectedLine: Line{
 				Chr: "1", Start: 0, Stop: 100,
-				Full: []string{"1", "0", "100"},
 			},
 			expectedChrInMap: true,
 		},
@@ -561,11 +515,9 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 50, Stop: 51,
-				Full: []string{"1", "50", "51"},
 			},
 			expectedLine: Line{
 				Chr: "1", Start: 1, Stop: 100,
-				Full: []string{"1", "1", "100"},
 			},
 			expectedChrInMap: true,
 		},
@@ -580,11 +532,9 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 50, Stop: 51,
-				Full: []string{"1", "50", "51"},
 			},
 			expectedLine: Line{
 				Chr: "1", Start: 40, Stop: 61,
-				Full: []string{"1", "40", "61"},
 			},
 			expectedChrInMap: false,
 		},
@@ -599,11 +549,9 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 50, Stop: 51,
-				Full: []string{"1", "50", "51"},
 			},
 			expectedLine: Line{
 				Chr: "1", Start: 40, Stop: 61,
-				Full: []string{"1", "40", "61"},
 			},
 			expectedChrInMap: false,
 		},
@@ -618,11 +566,9 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 40, Stop: 70,
-				Full: []string{"1", "40", "70"},
 			},
 			expectedLine: Line{
 				Chr: "1", Start: 50, Stop: 60,
-				Full: []string{"1", "50", "60"},
 			},
 			expectedChrInMap: true,
 		},
@@ -637,7 +583,93 @@ func TestPadLine(t *testing.T) {
 			},
 			line: Line{
 				Chr: "1", Start: 40, Stop: 70,
-				Full: []string{"1", "40", "70"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "upstream and downstream padding, plus strand",
+			bed: Bedfile{
+				PadUpstream:   10,
+				PadDownstream: 2,
+				chrLengthMap:  testChrLengthMap,
+			},
+			line: Line{
+				Chr: "1", Start: 50, Stop: 60,
+				Strand: "+",
+				Opt:    []byte("+"),
+			},
+			expectedLine: Line{
+				Chr: "1", Start: 40, Stop: 62,
+				Strand: "+",
+				Opt:    []byte("+"),
+			},
+			expectedChrInMap: true,
+		},
+		{
+			testing: "upstream and downstream padding, minus strand",
+			bed: Bedfile{
+				PadUpstream:   10,
+				PadDownstream: 2,
+				chrLengthMap:  testChrLengthMap,
+			},
+			line: Line{
+				Chr: "1", Start: 50, Stop: 60,
+				Strand: "-1",
+				Opt:    []byte("-1"),
+			},
+			expectedLine: Line{
+				Chr: "1", Start: 48, Stop: 70,
+				Strand: "-1",
+				Opt:    []byte("-1"),
+			},
+			expectedChrInMap: true,
+		},
+		{
+			testing: "upstream padding beyond chromosome, minus strand",
+			bed: Bedfile{
+				PadUpstream:  100,
+				chrLengthMap: testChrLengthMap,
+			},
+			line: Line{
+				Chr: "1", Start: 50, Stop: 60,
+				Strand: "-",
+				Opt:    []byte("-"),
+			},
+			expectedLine: Line{
+				Chr: "1", Start: 50, Stop: 100,
+				Strand: "-",
+				Opt:    []byte("-"),
+			},
+			expectedChrInMap: true,
+		},
+		{
+			testing: "left and right padding, minus strand",
+			bed: Bedfile{
+				PadLeft:      10,
+				PadRight:     2,
+				chrLengthMap: testChrLengthMap,
+			},
+			line: Line{
+				Chr: "1", Start: 50, Stop: 60,
+				Strand: "-",
+				Opt:    []byte("-"),
+			},
+			expectedLine: Line{
+				Chr: "1", Start: 40, Stop: 62,
+				Strand: "-",
+				Opt:    []byte("-"),
+			},
+			expectedChrInMap: true,
+		},
+		{
+			testing: "negative left padding resulting in start >= stop",
+			bed: Bedfile{
+				PadLeft:      -12,
+				PadRight:     2,
+				chrLengthMap: testChrLengthMap,
+			},
+			line: Line{
+				Chr: "1", Start: 50, Stop: 60,
 			},
 			shouldFail: true,
 		},
@@ -0,0 +1,87 @@
+package bed
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Lines on one chromosome
+//
+// Chromosomes are compared case insensitively by all sorting
+// types, so lines where the chromosomes only differ in case
+// are put in the same partition
+type partition struct {
+	chr   string
+	lines []Line
+}
+
+// Verify the number of threads
+func (bf Bedfile) verifyThreads() error {
+	if bf.Threads < 1 {
+		return fmt.Errorf("--threads must be at least 1: %d", bf.Threads)
+	}
+	return nil
+}
+
+// Partition lines by chromosome, in the order the
+// chromosomes are first found in the lines
+func partitionByChr(lines []Line) []partition {
+	var partitions []partition
+	idxs := map[string]int{}
+	for _, l := range lines {
+		chr := strings.ToLower(l.Chr)
+		idx, ok := idxs[chr]
+		if !ok {
+			idx = len(partitions)
+			idxs[chr] = idx
+			partitions = append(partitions, partition{chr: chr})
+		}
+		partitions[idx].lines = append(partitions[idx].lines, l)
+	}
+	return partitions
+}
+
+// Join the lines of the partitions in the order of the partitions
+func concatPartitions(partitions []partition) []Line {
+	var nrOfLines int
+	for _, p := range partitions {
+		nrOfLines += len(p.lines)
+	}
+	lines := make([]Line, 0, nrOfLines)
+	for _, p := range partitions {
+		lines = append(lines, p.lines...)
+	}
+	return lines
+}
+
+// Run f for the index of each partition on --threads goroutines
+//
+// If f fails for more than one partition the error of
+// the first of these partitions is returned, so that the
+// error does not depend on the order the goroutines finish in
+func (bf Bedfile) forEachPartition(partitions []partition, f func(i int) error) error {
+	errs := make([]error, len(partitions))
+	idxs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(bf.Threads, len(partitions)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idxs {
+				errs[i] = f(i)
+			}
+		}()
+	}
+	for i := range partitions {
+		idxs <- i
+	}
+	close(idxs)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
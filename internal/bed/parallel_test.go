@@ -0,0 +1,158 @@
+package bed
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// Bed file with regions on several chromosomes, where some
+// chromosomes only differ in case and some are not in testChrLengthMap
+func testParallelBed() string {
+	var sb strings.Builder
+	chrs := []string{"2", "10", "1", "chrX", "ChrX", "3", "chrx", "4", "5", "MT"}
+	for i := range 300 {
+		chr := chrs[i*7%len(chrs)]
+		start := i * 13 % 90
+		strand := []string{"+", "-"}[i%2]
+		fmt.Fprintf(&sb, "%s\t%d\t%d\t%s\tgene%d\n", chr, start, start+i%11+1, strand, i%3)
+	}
+	return sb.String()
+}
+
+func TestThreadsGiveSameOutput(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing string
+		bf      Bedfile
+	}
+	testCases := []testCase{
+		{
+			testing: "lexicographic sorting",
+			bf:      Bedfile{SortType: LexST},
+		},
+		{
+			testing: "natural sorting with strand and feature",
+			bf:      Bedfile{SortType: NatST, StrandCol: 3, FeatCol: 4},
+		},
+		{
+			testing: "custom chromosome sorting with overlap",
+			bf:      Bedfile{SortType: CcsST, Overlap: -1, chrOrderMap: chrOrderToMap([]string{"chrX", "3", "1"})},
+		},
+		{
+			testing: "fasta index sorting with padding",
+			bf: Bedfile{
+				SortType: FidxST, Padding: 5, PaddingType: LaxPT,
+				chrLengthMap: testChrLengthMap, chrOrderMap: chrOrderToMap([]string{"1", "2", "3", "4"}),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var expectedWarnings, receivedWarnings bytes.Buffer
+			expected := tc.bf
+			expected.Threads = 1
+			expected.SetWarningWriter(&expectedWarnings)
+			received := tc.bf
+			received.Threads = 4
+			received.SetWarningWriter(&receivedWarnings)
+			for _, bf := range []*Bedfile{&expected, &received} {
+				if err := bf.readBed(strings.NewReader(testParallelBed())); err != nil {
+					t.Fatal(err)
+				}
+				if err := bf.MergeAndPadLines(); err != nil {
+					t.Fatal(err)
+				}
+				if err := bf.Sort(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := deep.Equal(expected.toString(), received.toString()); diff != nil {
+				t.Error("expected VS received file content", diff)
+			}
+			if diff := deep.Equal(expectedWarnings.String(), receivedWarnings.String()); diff != nil {
+				t.Error("expected VS received warnings", diff)
+			}
+		})
+	}
+}
+
+func TestPartitionByChr(t *testing.T) {
+	t.Parallel()
+	lines := []Line{
+		{Chr: "2", Start: 1, Stop: 5},
+		{Chr: "chrX", Start: 1, Stop: 5},
+		{Chr: "2", Start: 10, Stop: 15},
+		{Chr: "chrx", Start: 1, Stop: 5},
+	}
+	expectedPartitions := []partition{
+		{chr: "2", lines: []Line{lines[0], lines[2]}},
+		{chr: "chrx", lines: []Line{lines[1], lines[3]}},
+	}
+	partitions := partitionByChr(lines)
+	if diff := deep.Equal(expectedPartitions, partitions); diff != nil {
+		t.Error("expected VS received partitions", diff)
+	}
+	expectedLines := []Line{lines[0], lines[2], lines[1], lines[3]}
+	if diff := deep.Equal(expectedLines, concatPartitions(partitions)); diff != nil {
+		t.Error("expected VS received lines", diff)
+	}
+}
+
+func TestMergeAndPadLinesWithThreadsFails(t *testing.T) {
+	t.Parallel()
+	bf := Bedfile{
+		Threads: 2, Padding: 5, PaddingType: SafePT, chrLengthMap: testChrLengthMap,
+		Lines: []Line{
+			{Chr: "1", Start: 1, Stop: 5},
+			{Chr: "5", Start: 1, Stop: 5},
+		},
+	}
+	if err := bf.MergeAndPadLines(); err == nil {
+		t.Fatal("expected error for chromosome not in the chromosome length map")
+	}
+}
+
+func TestVerifyThreads(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		threads    int
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "one thread",
+			threads: 1,
+		},
+		{
+			testing: "several threads",
+			threads: 4,
+		},
+		{
+			testing:    "no threads",
+			threads:    0,
+			shouldFail: true,
+		},
+		{
+			testing:    "negative threads",
+			threads:    -1,
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := Bedfile{Threads: tc.threads}.verifyThreads()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package bed
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Strands to query
+var PlusQS = "+"  // only lines on the plus strand (+ or 1)
+var MinusQS = "-" // only lines on the minus strand (- or -1)
+
+type Query struct {
+	Region      []string `short:"r" group:"query" sep:"none" help:"Region to look up, given as chr:start-end (1-based and inclusive, like in samtools and genome browsers, e.g. chr7:55,019,017-55,211,628), chr:pos for a single base or chr for the whole chromosome. Several regions can be given comma separated or by repeating the flag. Commas followed by three digits are read as thousands separators"`
+	RegionsFile []string `env:"REGIONS_FILE" group:"query" help:"Bed file path(s) with regions to look up. Only the chromosome, start and stop columns are used"`
+	Strand      string   `env:"QUERY_STRAND" group:"query" enum:",${plusQS},${minusQS}" default:"" help:"Only report lines on this strand. ${plusQS} = the plus strand (+ or 1), ${minusQS} = the minus strand (- or -1). Must be used together with --strand-col"`
+
+	// The regions given by --region
+	regions []Line
+}
+
+// A comma followed by three digits (and the end of the
+// region or a -) continues the number before it
+var thousandsPattern = regexp.MustCompile(`^[0-9]{3}(-|$)`)
+
+// Verifies and handles Query input
+func (q *Query) VerifyAndHandle() error {
+	if len(q.Region) == 0 && len(q.RegionsFile) == 0 {
+		return fmt.Errorf("at least one --region or --regions-file must be given")
+	}
+	for _, region := range splitRegions(q.Region) {
+		l, err := parseRegion(region)
+		if err != nil {
+			return err
+		}
+		q.regions = append(q.regions, l)
+	}
+	for i, file := range q.RegionsFile {
+		q.RegionsFile[i] = filepath.Clean(file)
+	}
+	return nil
+}
+
+// Split comma separated regions, keeping
+// the thousands separators of the positions
+func splitRegions(regions []string) []string {
+	var split []string
+	for _, r := range regions {
+		for i, part := range strings.Split(r, ",") {
+			if i > 0 && thousandsPattern.MatchString(part) {
+				split[len(split)-1] += part
+				continue
+			}
+			split = append(split, part)
+		}
+	}
+	return split
+}
+
+// Parse a region string (chr:start-end, chr:pos or chr) with 1-based
+// inclusive positions into a line with 0-based half-open coordinates
+func parseRegion(region string) (Line, error) {
+	region = strings.TrimSpace(region)
+	if region == "" {
+		return Line{}, fmt.Errorf("empty region")
+	}
+	// Chromosome names can contain :, so the positions
+	// follow the last : like in samtools
+	sep := strings.LastIndex(region, ":")
+	if sep < 0 {
+		return Line{Chr: region, Start: 0, Stop: math.MaxInt}, nil
+	}
+	chr, positions := region[:sep], region[sep+1:]
+	if chr == "" {
+		return Line{}, fmt.Errorf("missing chromosome in region %q", region)
+	}
+	startText, stopText, isRange := strings.Cut(positions, "-")
+	if !isRange {
+		stopText = startText
+	}
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return Line{}, fmt.Errorf("non-int start in region %q: %s", region, startText)
+	}
+	stop, err := strconv.Atoi(stopText)
+	if err != nil {
+		return Line{}, fmt.Errorf("non-int end in region %q: %s", region, stopText)
+	}
+	if start < 1 {
+		return Line{}, fmt.Errorf("start must be at least 1 in region %q", region)
+	}
+	if stop < start {
+		return Line{}, fmt.Errorf("end is before start in region %q", region)
+	}
+	return Line{Chr: chr, Start: start - 1, Stop: stop}, nil
+}
+
+// Keep only the lines overlapping the regions of the query
+//
+// The lines are indexed by chromosome, and each line is kept once even
+// if it overlaps several regions. The lines keep their order, so that
+// they can be sorted by the chosen sorting type afterwards.
+func (bf *Bedfile) Query(q Query) error {
+	if q.Strand != "" && !stringInSlice([]string{PlusQS, MinusQS}, q.Strand) {
+		return fmt.Errorf("unknown query strand %s", q.Strand)
+	}
+	regions := make([]Line, 0, len(q.regions))
+	for _, r := range q.regions {
+		r.Chr = bf.resolveChr(r.Chr)
+		regions = append(regions, r)
+	}
+	if len(q.RegionsFile) > 0 {
+		other, err := bf.readRegions(q.RegionsFile)
+		if err != nil {
+			return err
+		}
+		regions = append(regions, other.Lines...)
+	}
+	bf.Lines = bf.queryLines(regions, q.Strand)
+	return nil
+}
+
+// Reading the bed files with the regions to look up, with
+// the same chromosome aliases as the inputs
+func (bf *Bedfile) readRegions(inputs []string) (Bedfile, error) {
+	regions := Bedfile{
+		Inputs:      inputs,
+		ChrAlias:    bf.ChrAlias,
+		ChrAliasSet: bf.ChrAliasSet,
+		chrAliases:  bf.chrAliases,
+		warnings:    bf.warnings,
+	}
+	err := regions.Read()
+	return regions, err
+}
+
+// Returns the lines overlapping at least one of the
+// regions, on the given strand if it is set
+func (bf *Bedfile) queryLines(regions []Line, strand string) []Line {
+	index := newRegionIndex(bf.Lines)
+	found := make([]bool, len(bf.Lines))
+	for _, r := range regions {
+		for _, idx := range index.overlappingIdxs(r.Chr, r.Start, r.Stop) {
+			found[idx] = true
+		}
+	}
+	var queried []Line
+	for i, l := range bf.Lines {
+		if !found[i] {
+			continue
+		}
+		if strand == PlusQS && l.Strand != "+" && l.Strand != "1" {
+			continue
+		}
+		if strand == MinusQS && !isMinusStrand(l.Strand) {
+			continue
+		}
+		queried = append(queried, l)
+	}
+	return queried
+}
@@ -0,0 +1,178 @@
+package bed
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestQueryVerifyAndHandle(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		query           Query
+		expectedRegions []Line
+		shouldFail      bool
+	}
+	testCases := []testCase{
+		{
+			testing: "region with thousands separators",
+			query:   Query{Region: []string{"chr7:55,019,017-55,211,628"}},
+			expectedRegions: []Line{
+				{Chr: "chr7", Start: 55019016, Stop: 55211628},
+			},
+		},
+		{
+			testing: "comma separated and repeated regions",
+			query:   Query{Region: []string{"1:1,000-2,000,2:5-10,X", "MT:100"}},
+			expectedRegions: []Line{
+				{Chr: "1", Start: 999, Stop: 2000},
+				{Chr: "2", Start: 4, Stop: 10},
+				{Chr: "X", Start: 0, Stop: math.MaxInt},
+				{Chr: "MT", Start: 99, Stop: 100},
+			},
+		},
+		{
+			testing: "chromosome name containing a colon",
+			query:   Query{Region: []string{"HLA-A*01:01:01:01:10-20"}},
+			expectedRegions: []Line{
+				{Chr: "HLA-A*01:01:01:01", Start: 9, Stop: 20},
+			},
+		},
+		{
+			testing: "only regions file",
+			query:   Query{RegionsFile: []string{"some/../regions.bed"}},
+		},
+		{
+			testing:    "no regions",
+			shouldFail: true,
+		},
+		{
+			testing:    "non-int start",
+			query:      Query{Region: []string{"1:a-20"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "non-int end",
+			query:      Query{Region: []string{"1:10-b"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "start at 0",
+			query:      Query{Region: []string{"1:0-20"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "end before start",
+			query:      Query{Region: []string{"1:20-10"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "missing chromosome",
+			query:      Query{Region: []string{":10-20"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "empty region in list",
+			query:      Query{Region: []string{"1:10-20,"}},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.query.VerifyAndHandle()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedRegions, tc.query.regions); diff != nil {
+					t.Error("expected VS received regions", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryLines(t *testing.T) {
+	t.Parallel()
+	lines := []Line{
+		{Chr: "1", Start: 20, Stop: 30, Strand: "-1", Opt: []byte("-1\tB")},
+		{Chr: "1", Start: 1, Stop: 10, Strand: "+", Opt: []byte("+\tA")},
+		{Chr: "2", Start: 5, Stop: 8, Strand: "1", Opt: []byte("1\tC")},
+		{Chr: "1", Start: 0, Stop: 100, Strand: "-", Opt: []byte("-\tD")},
+	}
+	type testCase struct {
+		testing       string
+		regions       []Line
+		strand        string
+		expectedLines []Line
+	}
+	testCases := []testCase{
+		{
+			testing: "lines overlapping several regions are kept once in their order",
+			regions: []Line{
+				{Chr: "1", Start: 25, Stop: 26},
+				{Chr: "1", Start: 5, Stop: 22},
+			},
+			expectedLines: []Line{
+				{Chr: "1", Start: 20, Stop: 30, Strand: "-1", Opt: []byte("-1\tB")},
+				{Chr: "1", Start: 1, Stop: 10, Strand: "+", Opt: []byte("+\tA")},
+				{Chr: "1", Start: 0, Stop: 100, Strand: "-", Opt: []byte("-\tD")},
+			},
+		},
+		{
+			testing: "touching regions do not overlap",
+			regions: []Line{
+				{Chr: "1", Start: 10, Stop: 20},
+				{Chr: "2", Start: 0, Stop: 5},
+			},
+			expectedLines: []Line{
+				{Chr: "1", Start: 0, Stop: 100, Strand: "-", Opt: []byte("-\tD")},
+			},
+		},
+		{
+			testing: "plus strand",
+			regions: []Line{
+				{Chr: "1", Start: 0, Stop: math.MaxInt},
+				{Chr: "2", Start: 0, Stop: math.MaxInt},
+			},
+			strand: PlusQS,
+			expectedLines: []Line{
+				{Chr: "1", Start: 1, Stop: 10, Strand: "+", Opt: []byte("+\tA")},
+				{Chr: "2", Start: 5, Stop: 8, Strand: "1", Opt: []byte("1\tC")},
+			},
+		},
+		{
+			testing: "minus strand",
+			regions: []Line{
+				{Chr: "1", Start: 0, Stop: math.MaxInt},
+				{Chr: "2", Start: 0, Stop: math.MaxInt},
+			},
+			strand: MinusQS,
+			expectedLines: []Line{
+				{Chr: "1", Start: 20, Stop: 30, Strand: "-1", Opt: []byte("-1\tB")},
+				{Chr: "1", Start: 0, Stop: 100, Strand: "-", Opt: []byte("-\tD")},
+			},
+		},
+		{
+			testing: "unknown chromosome",
+			regions: []Line{
+				{Chr: "3", Start: 0, Stop: math.MaxInt},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			bf := Bedfile{Lines: deepCopyLines(lines)}
+			queried := bf.queryLines(tc.regions, tc.strand)
+			if diff := deep.Equal(tc.expectedLines, queried); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
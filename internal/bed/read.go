@@ -2,12 +2,11 @@ package bed
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
-	"os"
 	"regexp"
 	"strconv"
-	"strings"
 )
 
 // Bed file constants
@@ -17,20 +16,97 @@ const (
 	stopIdx  = 2
 )
 
+// Accepted values of the strand column
+var strandPattern = regexp.MustCompile(`^(\.|\+|-|\+1|-1|1)$`)
+
 // Opening and reading the bed files and optional fasta index file
 func (bf *Bedfile) Read() error {
+	if err := bf.verifyNotBedpe(); err != nil {
+		return err
+	}
+	if err := bf.readChrAliasFile(); err != nil {
+		return err
+	}
 	for _, input := range bf.Inputs {
-		bedFile, err := os.Open(input)
+		bedFile, err := openInput(input)
 		if err != nil {
 			return err
 		}
 		defer bedFile.Close()
+		bf.currentInput = input
 		if err := bf.readBed(bedFile); err != nil {
 			return fmt.Errorf("can't read bed file %s: %q", input, err)
 		}
 	}
+	if err := bf.readFastaIdxFile(); err != nil {
+		return err
+	}
+	if err := bf.VerifyVcfChrLengths(); err != nil {
+		return err
+	}
+	bf.chrAliasWarnings()
+	return nil
+}
+
+// Reading a bed file from a reader instead of the input paths,
+// decompressing it if it is gzip or BGZF compressed
+//
+// The name is used in messages and to find the column mapping
+// of the file (see --col-map). A chromosome alias file
+// must be read before the bed files for the aliases to be used
+func (bf *Bedfile) ReadBedFrom(name string, r io.Reader) error {
+	if err := bf.verifyNotBedpe(); err != nil {
+		return err
+	}
+	if err := bf.readChrAliasFile(); err != nil {
+		return err
+	}
+	reader, gzipReader, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("can't read bed file %s: %q", name, err)
+	}
+	if gzipReader != nil {
+		defer gzipReader.Close()
+	}
+	bf.currentInput = name
+	if err := bf.readBed(reader); err != nil {
+		return fmt.Errorf("can't read bed file %s: %q", name, err)
+	}
+	bf.chrAliasWarnings()
+	return nil
+}
+
+// Reading a fasta index file from a reader instead of the
+// fasta index path, decompressing it if it is gzip or BGZF compressed
+//
+// The name is used as the fasta index file in messages
+func (bf *Bedfile) ReadFastaIdxFrom(name string, r io.Reader) error {
+	if err := bf.readChrAliasFile(); err != nil {
+		return err
+	}
+	bf.FastaIdx = name
+	reader, gzipReader, err := decompress(r)
+	if err != nil {
+		return fmt.Errorf("can't read fasta index file %s: %q", name, err)
+	}
+	if gzipReader != nil {
+		defer gzipReader.Close()
+	}
+	if err := bf.readFastaIdx(reader); err != nil {
+		return fmt.Errorf("can't read fasta index file %s: %q", name, err)
+	}
+	bf.chrAliasWarnings()
+	return nil
+}
+
+// Opening and reading the fasta index file if it is set,
+// or scanning the fasta file if --fasta is set
+func (bf *Bedfile) readFastaIdxFile() error {
+	if bf.Fasta != "" {
+		return bf.readFastaFile()
+	}
 	if bf.FastaIdx != "" {
-		fastaIdxFile, err := os.Open(bf.FastaIdx)
+		fastaIdxFile, err := openInput(bf.FastaIdx)
 		if err != nil {
 			return err
 		}
@@ -44,125 +120,181 @@ func (bf *Bedfile) Read() error {
 
 // Reading the bed file
 func (bf *Bedfile) readBed(file io.Reader) error {
-	var err error
 	var expectedNrOfCols int
 
-	minNrCols := 3
-	headerPattern := regexp.MustCompile(`^(browser|track|#)`)
-	strandPattern := regexp.MustCompile(`^(\.|\+|-|\+1|-1|1)$`)
-
 	// If there is already content in bf save the expectedNrOfCols
 	if len(bf.Lines) != 0 {
-		expectedNrOfCols = len(bf.Lines[0].Full)
+		expectedNrOfCols = bf.Lines[0].nrOfCols()
 	}
 
+	return bf.scanBed(file, &expectedNrOfCols, func(l Line, _ int) error {
+		bf.Lines = append(bf.Lines, l)
+		return nil
+	})
+}
+
+// Scanning the bed file and passing each line to handle
+//
+// Header lines before the first region of each file, and browser and
+// track lines anywhere in the file, are handled according to
+// --header-policy, while comments after the first region are handled
+// according to --comment-policy. expectedNrOfCols is shared between
+// calls so that joined files are verified to have the same number of
+// columns.
+//
+// The lines are parsed without splitting them into columns, and only
+// the optional columns are copied, so that each line allocates as
+// little as possible
+func (bf *Bedfile) scanBed(file io.Reader, expectedNrOfCols *int, handle func(l Line, lineNr int) error) error {
+	var err error
+
+	minNrCols := 3
+
+	// The number of columns is known when regions have been read
+	// from an earlier file, otherwise this is the first file
+	firstFile := *expectedNrOfCols == 0
+	regionsRead := false
 	lineNr := 0
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		var l Line
 		lineNr++
 
-		lineText := scanner.Text()
+		line := scanner.Bytes()
 
-		// Handle headers
-		if headerPattern.MatchString(lineText) && len(bf.Lines) == 0 {
-			bf.Header = append(bf.Header, lineText)
+		// Convert lines of other input formats to bed lines, their
+		// comments and meta-information are not kept as headers
+		if bf.InputFormat == GFF3IF && string(line) == "##FASTA" {
+			// The rest of a GFF3 file contains sequences
+			break
+		}
+		var keep bool
+		line, keep, err = bf.toBedLine(line, lineNr)
+		if err != nil {
+			return err
+		}
+		if !keep {
 			continue
 		}
 
-		// Split line
-		l.Full = strings.Split(lineText, "\t")
+		// Handle headers and comments
+		if headerPattern.Match(line) {
+			if regionsRead && commentPattern.Match(line) {
+				bf.addComment(string(line))
+			} else {
+				bf.addHeader(string(line), firstFile)
+			}
+			continue
+		}
+		regionsRead = true
+
+		// Project line to the schema
+		line = bf.projectText(line)
 
 		// For the first non-header line save the number of columns
-		if lineNr == len(bf.Header)+1 && expectedNrOfCols == 0 {
-			expectedNrOfCols = len(l.Full)
-			if expectedNrOfCols < minNrCols {
-				return fmt.Errorf("less than %d columns on line %d: %s", minNrCols, lineNr, lineText)
+		nrOfCols := bytes.Count(line, []byte("\t")) + 1
+		if *expectedNrOfCols == 0 {
+			*expectedNrOfCols = nrOfCols
+			if *expectedNrOfCols < minNrCols {
+				return fmt.Errorf("less than %d columns on line %d: %s", minNrCols, lineNr, line)
 			}
 		}
-		if len(l.Full) != expectedNrOfCols {
+		if nrOfCols != *expectedNrOfCols {
 			return fmt.Errorf("expected %d columns on line %d got %d: %s",
-				expectedNrOfCols, lineNr, len(l.Full), lineText)
+				*expectedNrOfCols, lineNr, nrOfCols, line)
 		}
 
 		// Fill struct
-		l.Chr = l.Full[chrIdx]
-		l.Start, err = strconv.Atoi(l.Full[startIdx])
+		chr, rest, _ := bytes.Cut(line, []byte("\t"))
+		start, rest, _ := bytes.Cut(rest, []byte("\t"))
+		stop, opt, hasOpt := bytes.Cut(rest, []byte("\t"))
+		l.Chr = bf.resolveChr(bf.intern(chr))
+		l.Start, err = strconv.Atoi(string(start))
 		if err != nil {
-			return fmt.Errorf("non-int start position on line %d: %s", lineNr, l.Full[startIdx])
+			return fmt.Errorf("non-int start position on line %d: %s", lineNr, start)
 		}
-		l.Stop, err = strconv.Atoi(l.Full[stopIdx])
+		l.Stop, err = strconv.Atoi(string(stop))
 		if err != nil {
-			return fmt.Errorf("non-int stop position on line %d: %s", lineNr, l.Full[stopIdx])
+			return fmt.Errorf("non-int stop position on line %d: %s", lineNr, stop)
+		}
+		if hasOpt {
+			l.Opt = bytes.Clone(opt)
 		}
 		// Verify start and stop
 		if l.Start > l.Stop {
 			return fmt.Errorf("stop is greater than start on line %d: %d > %d\n", lineNr, l.Start, l.Stop)
 		}
 		if l.Start == l.Stop {
-			fmt.Fprintf(os.Stderr, "warning: start and stop is equal on line %d: %d == %d\n", lineNr, l.Start, l.Stop)
+			bf.warn("start and stop is equal on line %d: %d == %d", lineNr, l.Start, l.Stop)
 		}
 		// Set strand and feature if selected
 		if bf.StrandCol > stopIdx {
-			if bf.StrandCol > len(l.Full)-1 {
-				return fmt.Errorf("given strand column, %d, is outside bed file (nr columns=%d)", bf.StrandCol+1, len(l.Full))
+			if bf.StrandCol > nrOfCols-1 {
+				return fmt.Errorf("given strand column, %d, is outside bed file (nr columns=%d)", bf.StrandCol+1, nrOfCols)
 			}
-			l.Strand = l.Full[bf.StrandCol]
+			strand, _ := nthField(l.Opt, bf.StrandCol-firstOptIdx)
 			// Verify strand format
-			if !strandPattern.MatchString(l.Strand) {
-				return fmt.Errorf("unexpected strand format on line %d: %s", lineNr, l.Strand)
+			if !strandPattern.Match(strand) {
+				return fmt.Errorf("unexpected strand format on line %d: %s", lineNr, strand)
 			}
+			l.Strand = bf.intern(strand)
 		}
 		if bf.FeatCol > stopIdx {
-			if bf.FeatCol > len(l.Full)-1 {
-				return fmt.Errorf("given strand column, %d, is outside bed file (nr columns=%d)", bf.FeatCol+1, len(l.Full))
+			if bf.FeatCol > nrOfCols-1 {
+				return fmt.Errorf("given strand column, %d, is outside bed file (nr columns=%d)", bf.FeatCol+1, nrOfCols)
 			}
-			l.Feat = l.Full[bf.FeatCol]
+			feat, _ := nthField(l.Opt, bf.FeatCol-firstOptIdx)
+			l.Feat = bf.intern(feat)
+		}
+		if bf.Bed12 {
+			if err := verifyBed12Line(l, lineNr); err != nil {
+				return err
+			}
+		}
+		l.Comments = bf.takeComments()
+		if err := handle(l, lineNr); err != nil {
+			return err
 		}
-		bf.Lines = append(bf.Lines, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
 	}
 	return nil
 }
 
-// Reading the fasta index file
-func (bf *Bedfile) readFastaIdx(file io.Reader) error {
-	var chrOrder []string
-
-	minNrCols := 2
-	chrLengthMap := map[string]int{}
-
-	const (
-		chrFIdx  = 0
-		sizeFIdx = 1
-	)
-
-	lineNr := 0
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lineNr++
-
-		lineText := scanner.Text()
-
-		// Split line
-		cols := strings.Split(lineText, "\t")
-
-		// For the first content line set the number of columns if it is empty
-		if len(cols) < minNrCols {
-			return fmt.Errorf("expected at least %d columns on line %d got %d: %s",
-				minNrCols, lineNr, len(cols), lineText)
-		}
+// Convert a line of the input format to a bed line, returns
+// false if the line should be skipped
+func (bf *Bedfile) toBedLine(line []byte, lineNr int) ([]byte, bool, error) {
+	var lineText string
+	var keep bool
+	var err error
+	switch bf.InputFormat {
+	case GFF3IF, GTFIF:
+		lineText, keep, err = bf.annotationToBed(string(line), lineNr)
+	case VcfIF:
+		lineText, keep, err = bf.vcfToBed(string(line), lineNr)
+	default:
+		return line, true, nil
+	}
+	return []byte(lineText), keep, err
+}
 
-		// Put chromosome sizes in map and record chromosome order
-		size, err := strconv.Atoi(cols[sizeFIdx])
-		if err != nil {
-			return fmt.Errorf("non-int size for chr %s on line %d: %s", cols[chrFIdx], lineNr, cols[sizeFIdx])
-		}
-		chrLengthMap[cols[chrFIdx]] = size
-		chrOrder = append(chrOrder, cols[chrFIdx])
+// Reading the fasta index file, or one of the other genome
+// files with the chromosome sizes (see readGenome)
+func (bf *Bedfile) readFastaIdx(file io.Reader) error {
+	chrOrder, chrLengthMap, err := bf.readGenome(file)
+	if err != nil {
+		return err
 	}
+	return bf.setChrLengths(chrOrder, chrLengthMap)
+}
+
+// Set the chromosome lengths, and the chromosome
+// order if --sorting-type=fidx
+func (bf *Bedfile) setChrLengths(chrOrder []string, chrLengthMap map[string]int) error {
 	// Check that file is not empty
-	if lineNr == 0 {
-		return fmt.Errorf("fasta index file %s is empty", bf.FastaIdx)
+	if len(chrOrder) == 0 {
+		return fmt.Errorf("%s is empty", bf.chrLengthsName())
 	}
 
 	// Overwrite chr order map if --sorting-type=fidx
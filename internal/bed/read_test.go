@@ -31,19 +31,15 @@ func TestReadBed(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
-						Full: []string{"2", "20", "200"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 				},
 			},
@@ -62,19 +58,15 @@ func TestReadBed(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 200, Stop: 200,
-						Full: []string{"2", "200", "200"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 				},
 			},
@@ -101,19 +93,15 @@ func TestReadBed(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
-						Full: []string{"2", "20", "200"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 				},
 			},
@@ -137,22 +125,22 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 				},
 			},
@@ -167,22 +155,22 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 				},
 			},
@@ -198,42 +186,42 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 					{
 						Chr: "5", Start: 50, Stop: 500,
 						Strand: "-1", Feat: "E",
-						Full: []string{"5", "50", "500", "-1", "E"},
+						Opt: []byte("-1\tE"),
 					},
 					{
 						Chr: "6", Start: 60, Stop: 600,
 						Strand: "-1", Feat: "F",
-						Full: []string{"6", "60", "600", "-1", "F"},
+						Opt: []byte("-1\tF"),
 					},
 					{
 						Chr: "7", Start: 70, Stop: 700,
 						Strand: "1", Feat: "G",
-						Full: []string{"7", "70", "700", "1", "G"},
+						Opt: []byte("1\tG"),
 					},
 					{
 						Chr: "8", Start: 80, Stop: 800,
 						Strand: "1", Feat: "H",
-						Full: []string{"8", "80", "800", "1", "H"},
+						Opt: []byte("1\tH"),
 					},
 				},
 			},
@@ -253,22 +241,22 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 				},
 			},
@@ -289,42 +277,42 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 					{
 						Chr: "5", Start: 50, Stop: 500,
 						Strand: "-1", Feat: "E",
-						Full: []string{"5", "50", "500", "-1", "E"},
+						Opt: []byte("-1\tE"),
 					},
 					{
 						Chr: "6", Start: 60, Stop: 600,
 						Strand: "-1", Feat: "F",
-						Full: []string{"6", "60", "600", "-1", "F"},
+						Opt: []byte("-1\tF"),
 					},
 					{
 						Chr: "7", Start: 70, Stop: 700,
 						Strand: "1", Feat: "G",
-						Full: []string{"7", "70", "700", "1", "G"},
+						Opt: []byte("1\tG"),
 					},
 					{
 						Chr: "8", Start: 80, Stop: 800,
 						Strand: "1", Feat: "H",
-						Full: []string{"8", "80", "800", "1", "H"},
+						Opt: []byte("1\tH"),
 					},
 				},
 			},
@@ -352,32 +340,32 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 860259, Stop: 879955,
 						Strand: "1", Feat: "ENSG00000187634",
-						Full: []string{"1", "860259", "879955", "1", "SAMD11", "ENSG00000187634"},
+						Opt: []byte("1\tSAMD11\tENSG00000187634"),
 					},
 					{
 						Chr: "1", Start: 948802, Stop: 949920,
 						Strand: "1", Feat: "ENSG00000187608",
-						Full: []string{"1", "948802", "949920", "1", "ISG15", "ENSG00000187608"},
+						Opt: []byte("1\tISG15\tENSG00000187608"),
 					},
 					{
 						Chr: "10", Start: 124768494, Stop: 124773587,
 						Strand: "1", Feat: "ENSG00000196177",
-						Full: []string{"10", "124768494", "124773587", "1", "ACADSB", "ENSG00000196177"},
+						Opt: []byte("1\tACADSB\tENSG00000196177"),
 					},
 					{
 						Chr: "10", Start: 124782049, Stop: 124817827,
 						Strand: "1", Feat: "ENSG00000196177",
-						Full: []string{"10", "124782049", "124817827", "1", "ACADSB", "ENSG00000196177"},
+						Opt: []byte("1\tACADSB\tENSG00000196177"),
 					},
 					{
 						Chr: "10", Start: 126085871, Stop: 126107545,
 						Strand: "-1", Feat: "ENSG00000065154",
-						Full: []string{"10", "126085871", "126107545", "-1", "OAT", "ENSG00000065154"},
+						Opt: []byte("-1\tOAT\tENSG00000065154"),
 					},
 					{
 						Chr: "X", Start: 135067597, Stop: 135129423,
 						Strand: "1", Feat: "ENSG00000198689",
-						Full: []string{"X", "135067597", "135129423", "1", "SLC9A6", "ENSG00000198689"},
+						Opt: []byte("1\tSLC9A6\tENSG00000198689"),
 					},
 				},
 			},
@@ -459,11 +447,34 @@ func TestReadBed(t *testing.T) {
 				"#something\n" +
 				"1\t10\t100\n" +
 				"2\t20\t200\n" +
-				"track something\n" +
+				"track something else\n" +
 				"#something\n" +
 				"3\t30\t300\n" +
 				"4\t40\t400\n",
-			shouldFail: true,
+			expectedBed: Bedfile{
+				Inputs: []string{"test.bed"},
+				Header: []string{
+					"browser something",
+					"track something",
+					"#something",
+					"track something else",
+				},
+				Lines: []Line{
+					{
+						Chr: "1", Start: 10, Stop: 100,
+					},
+					{
+						Chr: "2", Start: 20, Stop: 200,
+					},
+					{
+						Chr: "3", Start: 30, Stop: 300,
+						Comments: []string{"#something"},
+					},
+					{
+						Chr: "4", Start: 40, Stop: 400,
+					},
+				},
+			},
 		},
 		{
 			testing: "strand in incorrect format",
@@ -514,22 +525,22 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 				},
 			},
@@ -554,22 +565,22 @@ func TestReadBed(t *testing.T) {
 					{
 						Chr: "1", Start: 10, Stop: 100,
 						Strand: "-1", Feat: "A",
-						Full: []string{"1", "10", "100", "-1", "A"},
+						Opt: []byte("-1\tA"),
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
 						Strand: "-1", Feat: "B",
-						Full: []string{"2", "20", "200", "-1", "B"},
+						Opt: []byte("-1\tB"),
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
 						Strand: "1", Feat: "C",
-						Full: []string{"3", "30", "300", "1", "C"},
+						Opt: []byte("1\tC"),
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
 						Strand: "1", Feat: "D",
-						Full: []string{"4", "40", "400", "1", "D"},
+						Opt: []byte("1\tD"),
 					},
 				},
 			},
@@ -580,7 +591,58 @@ func TestReadBed(t *testing.T) {
 				"6\t60\t600\t-1\tF\n" +
 				"7\t70\t700\t1\tG\n" +
 				"8\t80\t800\t1\tH\n",
-			shouldFail: true,
+			expectedBed: Bedfile{
+				Inputs:    []string{"test.bed"},
+				StrandCol: 4 - 1,
+				FeatCol:   5 - 1,
+				Header: []string{
+					"browser something",
+					"track something",
+					"#something",
+				},
+				Lines: []Line{
+					{
+						Chr: "1", Start: 10, Stop: 100,
+						Strand: "-1", Feat: "A",
+						Opt: []byte("-1\tA"),
+					},
+					{
+						Chr: "2", Start: 20, Stop: 200,
+						Strand: "-1", Feat: "B",
+						Opt: []byte("-1\tB"),
+					},
+					{
+						Chr: "3", Start: 30, Stop: 300,
+						Strand: "1", Feat: "C",
+						Opt: []byte("1\tC"),
+					},
+					{
+						Chr: "4", Start: 40, Stop: 400,
+						Strand: "1", Feat: "D",
+						Opt: []byte("1\tD"),
+					},
+					{
+						Chr: "5", Start: 50, Stop: 500,
+						Strand: "-1", Feat: "E",
+						Opt: []byte("-1\tE"),
+					},
+					{
+						Chr: "6", Start: 60, Stop: 600,
+						Strand: "-1", Feat: "F",
+						Opt: []byte("-1\tF"),
+					},
+					{
+						Chr: "7", Start: 70, Stop: 700,
+						Strand: "1", Feat: "G",
+						Opt: []byte("1\tG"),
+					},
+					{
+						Chr: "8", Start: 80, Stop: 800,
+						Strand: "1", Feat: "H",
+						Opt: []byte("1\tH"),
+					},
+				},
+			},
 		},
 	}
 	for _, tc := range testCases {
@@ -592,6 +654,8 @@ func TestReadBed(t *testing.T) {
 				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
 			}
 			if !tc.shouldFail {
+				// The interned strings are tested by TestIntern
+				tc.bed.strs = nil
 				if diff := deep.Equal(tc.expectedBed, tc.bed); diff != nil {
 					t.Error("expected VS received bed", diff)
 				}
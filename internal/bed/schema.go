@@ -0,0 +1,136 @@
+package bed
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Verify the schema options and parse the column mappings
+//
+// Each column mapping is given as <file>=<col>,<col>,... where the
+// n-th column is the 1-based column of the file that is used as the
+// n-th column of the output, or . for a column filled with --schema-fill.
+// Files with a column mapping are projected to the width of the mapping,
+// which must be the same as --schema if it is set.
+func (bf *Bedfile) verifyAndHandleSchema() error {
+	if bf.Schema != 0 && bf.Schema < stopIdx+1 {
+		return fmt.Errorf("--schema must be at least 3: %d", bf.Schema)
+	}
+	if bf.Schema == 0 && len(bf.ColMap) == 0 {
+		return nil
+	}
+	if bf.SchemaFill == "" {
+		bf.SchemaFill = "."
+	}
+	if bf.InputFormat == BedpeIF {
+		return fmt.Errorf("--schema and --col-map can not be used together with --input-format=%s", BedpeIF)
+	}
+	if bf.Bed12 && bf.Schema != 0 && bf.Schema < nrOfB12Cols {
+		return fmt.Errorf("--schema must be at least %d together with --bed12: %d", nrOfB12Cols, bf.Schema)
+	}
+	width := bf.Schema
+	if width != 0 && (bf.StrandCol >= width || bf.FeatCol >= width) {
+		return fmt.Errorf("--strand-col and --feat-col must be within the %d columns of --schema", width)
+	}
+	bf.colMaps = map[string][]int{}
+	for _, colMap := range bf.ColMap {
+		idx := strings.LastIndex(colMap, "=")
+		if idx < 1 {
+			return fmt.Errorf("--col-map must be given as <file>=<col>,<col>,...: %s", colMap)
+		}
+		file, err := bf.colMapInput(colMap[:idx])
+		if err != nil {
+			return err
+		}
+		var cols []int
+		for _, col := range strings.Split(colMap[idx+1:], ",") {
+			// Columns filled with --schema-fill are given as -1
+			if col == "." {
+				cols = append(cols, -1)
+				continue
+			}
+			c, err := strconv.Atoi(col)
+			if err != nil || c < 1 {
+				return fmt.Errorf("--col-map columns must be positive integers or .: %s", colMap)
+			}
+			cols = append(cols, c-1)
+		}
+		if len(cols) < stopIdx+1 || cols[chrIdx] == -1 || cols[startIdx] == -1 || cols[stopIdx] == -1 {
+			return fmt.Errorf("--col-map must map at least the chr, start and stop columns: %s", colMap)
+		}
+		if width == 0 {
+			width = len(cols)
+		}
+		if len(cols) != width {
+			return fmt.Errorf("--col-map must map %d columns, the same as --schema and the other column maps: %s", width, colMap)
+		}
+		if _, ok := bf.colMaps[file]; ok {
+			return fmt.Errorf("--col-map is given more than once for %s", file)
+		}
+		bf.colMaps[file] = cols
+	}
+	return nil
+}
+
+// Returns the input file a column mapping is given for
+//
+// The file is compared to the inputs by its absolute path, so that
+// e.g. /data/vendor.bed and ./vendor.bed are the same file. Without
+// inputs, as when reading from readers, the file is used as it is.
+func (bf Bedfile) colMapInput(file string) (string, error) {
+	file = filepath.Clean(file)
+	if len(bf.Inputs) == 0 {
+		return file, nil
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	for _, input := range bf.Inputs {
+		absInput, err := filepath.Abs(input)
+		if err != nil {
+			return "", err
+		}
+		if absFile == absInput {
+			return filepath.Clean(input), nil
+		}
+	}
+	return "", fmt.Errorf("--col-map is given for %s, which is not one of the input files", file)
+}
+
+// Project the columns of a line from the current input file
+// to the schema, returns the columns unchanged if no schema is used
+func (bf Bedfile) project(cols []string) []string {
+	colMap, ok := bf.colMaps[filepath.Clean(bf.currentInput)]
+	if !ok && bf.Schema == 0 {
+		return cols
+	}
+	width := bf.Schema
+	if ok {
+		width = len(colMap)
+	}
+	projected := make([]string, width)
+	for i := range projected {
+		idx := i
+		if ok {
+			idx = colMap[i]
+		}
+		if idx >= 0 && idx < len(cols) {
+			projected[i] = cols[idx]
+		} else {
+			projected[i] = bf.SchemaFill
+		}
+	}
+	return projected
+}
+
+// Project a tab separated line from the current input file
+// to the schema, returns the line unchanged if no schema is used
+func (bf Bedfile) projectText(line []byte) []byte {
+	if _, ok := bf.colMaps[filepath.Clean(bf.currentInput)]; !ok && bf.Schema == 0 {
+		return line
+	}
+	return []byte(strings.Join(bf.project(strings.Split(string(line), "\t")), "\t"))
+}
@@ -0,0 +1,180 @@
+package bed
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestVerifyAndHandleSchema(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		bf              Bedfile
+		expectedColMaps map[string][]int
+		shouldFail      bool
+	}
+	absVendor, err := filepath.Abs("a/vendor.bed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []testCase{
+		{
+			testing: "no schema",
+			bf:      Bedfile{},
+		},
+		{
+			testing:         "schema without column maps",
+			bf:              Bedfile{Schema: 6},
+			expectedColMaps: map[string][]int{},
+		},
+		{
+			testing: "column maps",
+			bf: Bedfile{ColMap: []string{
+				"a/vendor.bed=1,3,2,.,5,4",
+				"./other.bed=2,3,4,.,.,.",
+			}},
+			expectedColMaps: map[string][]int{
+				"a/vendor.bed": {0, 2, 1, -1, 4, 3},
+				"other.bed":    {1, 2, 3, -1, -1, -1},
+			},
+		},
+		{
+			testing: "column maps matched to the inputs by absolute path",
+			bf: Bedfile{
+				Inputs: []string{"a/vendor.bed", "other.bed"},
+				ColMap: []string{absVendor + "=1,3,2"},
+			},
+			expectedColMaps: map[string][]int{
+				"a/vendor.bed": {0, 2, 1},
+			},
+		},
+		{
+			testing: "column map for a file that is not an input",
+			bf: Bedfile{
+				Inputs: []string{"a/vendor.bed", "other.bed"},
+				ColMap: []string{"b/vendor.bed=1,3,2"},
+			},
+			shouldFail: true,
+		},
+		{
+			testing:    "schema less than 3 columns",
+			bf:         Bedfile{Schema: 2},
+			shouldFail: true,
+		},
+		{
+			testing:    "strand column outside the schema",
+			bf:         Bedfile{Schema: 4, StrandCol: 6 - 1},
+			shouldFail: true,
+		},
+		{
+			testing:    "column map with a different width than the schema",
+			bf:         Bedfile{Schema: 4, ColMap: []string{"vendor.bed=1,2,3"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "column maps with different widths",
+			bf:         Bedfile{ColMap: []string{"a.bed=1,2,3", "b.bed=1,2,3,4"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "column map without the stop column",
+			bf:         Bedfile{ColMap: []string{"vendor.bed=1,2,.,4"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "column map without a file",
+			bf:         Bedfile{ColMap: []string{"1,2,3"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "column map with a non-int column",
+			bf:         Bedfile{ColMap: []string{"vendor.bed=1,2,3,x"}},
+			shouldFail: true,
+		},
+		{
+			testing:    "schema with bedpe",
+			bf:         Bedfile{Schema: 6, InputFormat: BedpeIF},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bf.verifyAndHandleSchema()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedColMaps, tc.bf.colMaps); diff != nil {
+					t.Error("expected VS received column maps", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestReadBedWithSchema(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bf            Bedfile
+		files         map[string]string
+		expectedLines []string
+	}
+	files := map[string]string{
+		"bed3.bed":   "1\t10\t20\n",
+		"bed6.bed":   "1\t15\t30\tG1\t0\t+\n",
+		"vendor.txt": "1\t45\t40\t-\tV1\n",
+	}
+	testCases := []testCase{
+		{
+			testing: "truncated to BED4",
+			bf:      Bedfile{Schema: 4, SchemaFill: "NA"},
+			files:   map[string]string{"bed3.bed": files["bed3.bed"], "bed6.bed": files["bed6.bed"]},
+			expectedLines: []string{
+				"1\t10\t20\tNA",
+				"1\t15\t30\tG1",
+			},
+		},
+		{
+			testing: "column map",
+			bf:      Bedfile{Schema: 6, ColMap: []string{"vendor.txt=1,3,2,5,.,4"}},
+			files:   files,
+			expectedLines: []string{
+				"1\t10\t20\t.\t.\t.",
+				"1\t15\t30\tG1\t0\t+",
+				"1\t40\t45\tV1\t.\t-",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			if err := tc.bf.verifyAndHandleSchema(); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"bed3.bed", "bed6.bed", "vendor.txt"} {
+				content, ok := tc.files[name]
+				if !ok {
+					continue
+				}
+				tc.bf.currentInput = name
+				if err := tc.bf.readBed(strings.NewReader(content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var receivedLines []string
+			for _, l := range tc.bf.Lines {
+				receivedLines = append(receivedLines, l.text())
+			}
+			if diff := deep.Equal(tc.expectedLines, receivedLines); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
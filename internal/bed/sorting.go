@@ -21,52 +21,111 @@ var humanChrOrder = []string{"1", "chr1", "2", "chr2", "3", "chr3", "4", "chr4",
 // Global sorting function
 // Note: mergeSort() is missing from this list as it
 // is only intended for internal use
+//
+// With --threads the lines are partitioned by chromosome, the
+// partitions are sorted concurrently and then joined in the
+// chromosome order of the sorting type
 func (bf *Bedfile) Sort() error {
+	compare, err := bf.lineCompareFunc()
+	if err != nil {
+		return err
+	}
+	var partitions []partition
+	if bf.Threads > 1 {
+		partitions = partitionByChr(bf.Lines)
+	}
+	if len(partitions) <= 1 {
+		slices.SortStableFunc(bf.Lines, compare)
+		return nil
+	}
+	chrCompare, err := bf.chrCompareFunc()
+	if err != nil {
+		return err
+	}
+	// Sorting does not fail, so the error is always nil
+	_ = bf.forEachPartition(partitions, func(i int) error {
+		slices.SortStableFunc(partitions[i].lines, compare)
+		return nil
+	})
+	slices.SortStableFunc(partitions, func(a, b partition) int {
+		return chrCompare(a.chr, b.chr)
+	})
+	bf.Lines = concatPartitions(partitions)
+	return nil
+}
+
+// Returns the line comparison used by the selected sorting type
+func (bf *Bedfile) lineCompareFunc() (func(a, b Line) int, error) {
 	switch bf.SortType {
 	case LexST:
-		bf.Lines = lexicographicSort(bf.Lines)
+		return lexicographicCompare, nil
 	case NatST:
-		bf.Lines = naturalSort(bf.Lines)
+		return naturalCompare, nil
 	case CcsST, FidxST:
-		bf.Lines = customChrSort(bf.Lines, bf.chrOrderMap)
+		return func(a, b Line) int {
+			return customChrCompare(a, b, bf.chrOrderMap)
+		}, nil
 	default:
-		return fmt.Errorf("unknown sorting type %s", bf.SortType)
+		return nil, fmt.Errorf("unknown sorting type %s", bf.SortType)
+	}
+}
+
+// Returns the chromosome comparison used by the selected sorting type
+func (bf *Bedfile) chrCompareFunc() (func(a, b string) int, error) {
+	switch bf.SortType {
+	case LexST:
+		return func(a, b string) int {
+			return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+		}, nil
+	case NatST:
+		return naturalStringCompare, nil
+	case CcsST, FidxST:
+		return func(a, b string) int {
+			return stringMapCompare(a, b, bf.chrOrderMap)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown sorting type %s", bf.SortType)
 	}
-	return nil
 }
 
 // Lexicographic sorting
 // Sorting hierarchy: chr, start, stop, strand, feat
 // Chr sorting: 1 < 10 < 2 < MT < X
 func lexicographicSort(lines []Line) []Line {
-	slices.SortStableFunc(lines, func(a, b Line) int {
-		return cmp.Or(
-			cmp.Compare(strings.ToLower(a.Chr), strings.ToLower(b.Chr)),
-			cmp.Compare(a.Start, b.Start),
-			cmp.Compare(a.Stop, b.Stop),
-			cmp.Compare(a.Strand, b.Strand),
-			cmp.Compare(strings.ToLower(a.Feat), strings.ToLower(b.Feat)),
-		)
-	})
+	slices.SortStableFunc(lines, lexicographicCompare)
 	return lines
 }
 
+// Lexicographic comparison of lines
+func lexicographicCompare(a, b Line) int {
+	return cmp.Or(
+		cmp.Compare(strings.ToLower(a.Chr), strings.ToLower(b.Chr)),
+		cmp.Compare(a.Start, b.Start),
+		cmp.Compare(a.Stop, b.Stop),
+		cmp.Compare(a.Strand, b.Strand),
+		cmp.Compare(strings.ToLower(a.Feat), strings.ToLower(b.Feat)),
+	)
+}
+
 // Natural sorting
 // Sorting hierarchy: chr, start, stop, strand, feat
 // Chr sorting: 1 < 2 < 10 < MT < X
 func naturalSort(lines []Line) []Line {
-	slices.SortStableFunc(lines, func(a, b Line) int {
-		return cmp.Or(
-			naturalStringCompare(a.Chr, b.Chr),
-			cmp.Compare(a.Start, b.Start),
-			cmp.Compare(a.Stop, b.Stop),
-			cmp.Compare(a.Strand, b.Strand),
-			naturalStringCompare(a.Feat, b.Feat),
-		)
-	})
+	slices.SortStableFunc(lines, naturalCompare)
 	return lines
 }
 
+// Natural comparison of lines
+func naturalCompare(a, b Line) int {
+	return cmp.Or(
+		naturalStringCompare(a.Chr, b.Chr),
+		cmp.Compare(a.Start, b.Start),
+		cmp.Compare(a.Stop, b.Stop),
+		cmp.Compare(a.Strand, b.Strand),
+		naturalStringCompare(a.Feat, b.Feat),
+	)
+}
+
 // Custom chromosome sorting
 // Sorting hierarchy: chr, start, stop, strand, feat
 // Sorting chromosomes according to custom order map
@@ -74,17 +133,22 @@ func naturalSort(lines []Line) []Line {
 // of the lines in a natural sorting order
 func customChrSort(lines []Line, orderMap map[string]int) []Line {
 	slices.SortStableFunc(lines, func(a, b Line) int {
-		return cmp.Or(
-			stringMapCompare(a.Chr, b.Chr, orderMap),
-			cmp.Compare(a.Start, b.Start),
-			cmp.Compare(a.Stop, b.Stop),
-			cmp.Compare(a.Strand, b.Strand),
-			naturalStringCompare(a.Feat, b.Feat),
-		)
+		return customChrCompare(a, b, orderMap)
 	})
 	return lines
 }
 
+// Custom chromosome comparison of lines
+func customChrCompare(a, b Line, orderMap map[string]int) int {
+	return cmp.Or(
+		stringMapCompare(a.Chr, b.Chr, orderMap),
+		cmp.Compare(a.Start, b.Start),
+		cmp.Compare(a.Stop, b.Stop),
+		cmp.Compare(a.Strand, b.Strand),
+		naturalStringCompare(a.Feat, b.Feat),
+	)
+}
+
 // Sorting used before merging
 // Sorting hierarchy: feat, chr, strand, start, stop
 // Chr sorting: 1 < 10 < 2
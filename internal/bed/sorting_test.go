@@ -10,43 +10,33 @@ import (
 var testChrSort = []Line{
 	{
 		Chr: "chr10", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "chrX", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "HG987_PATCH", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "chr10", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "chrMT", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "GL000209.1", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "HG385_PATCH", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "chr2", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "GL000226.1", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 	{
 		Chr: "chr1", Start: 8, Stop: 9,
-		Full: []string{"1", "8", "9"},
 	},
 }
 
@@ -54,57 +44,57 @@ var testFullSort = []Line{
 	{
 		Chr: "2", Start: 12, Stop: 13,
 		Strand: "1", Feat: "C",
-		Full: []string{"2", "12", "13", "1", "C"},
+		Opt: []byte("1\tC"),
 	},
 	{
 		Chr: "X", Start: 10, Stop: 11,
 		Strand: "1", Feat: "A",
-		Full: []string{"X", "10", "11", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 8, Stop: 9,
 		Strand: "-1", Feat: "B",
-		Full: []string{"1", "8", "9", "-1", "B"},
+		Opt: []byte("-1\tB"),
 	},
 	{
 		Chr: "MT", Start: 10, Stop: 11,
 		Strand: "1", Feat: "A",
-		Full: []string{"MT", "10", "11", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "10", Start: 12, Stop: 13,
 		Strand: "1", Feat: "D",
-		Full: []string{"10", "12", "13", "1", "D"},
+		Opt: []byte("1\tD"),
 	},
 	{
 		Chr: "GL000209.1", Start: 10, Stop: 11,
 		Strand: "1", Feat: "A",
-		Full: []string{"GL000209.1", "10", "11", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 10, Stop: 11,
 		Strand: "-1", Feat: "A",
-		Full: []string{"1", "10", "11", "-1", "A"},
+		Opt: []byte("-1\tA"),
 	},
 	{
 		Chr: "1", Start: 12, Stop: 13,
 		Strand: "1", Feat: "A",
-		Full: []string{"1", "12", "13", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "HG385_PATCH", Start: 10, Stop: 11,
 		Strand: "1", Feat: "A",
-		Full: []string{"X", "10", "11", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 10, Stop: 11,
 		Strand: "1", Feat: "A",
-		Full: []string{"1", "10", "11", "1", "A"},
+		Opt: []byte("1\tA"),
 	},
 	{
 		Chr: "1", Start: 10, Stop: 11,
 		Strand: "-1", Feat: "B",
-		Full: []string{"1", "10", "11", "-1", "B"},
+		Opt: []byte("-1\tB"),
 	},
 }
 
@@ -122,43 +112,33 @@ func TestLexicographicSort(t *testing.T) {
 			expectedLines: []Line{
 				{
 					Chr: "chr1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr2", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrMT", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrX", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "GL000209.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "GL000226.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG385_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG987_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 			},
 		},
@@ -169,57 +149,57 @@ func TestLexicographicSort(t *testing.T) {
 				{
 					Chr: "1", Start: 8, Stop: 9,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "8", "9", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "A",
-					Full: []string{"1", "10", "11", "-1", "A"},
+					Opt: []byte("-1\tA"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "10", "11", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "1", Start: 12, Stop: 13,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "12", "13", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "10", Start: 12, Stop: 13,
 					Strand: "1", Feat: "D",
-					Full: []string{"10", "12", "13", "1", "D"},
+					Opt: []byte("1\tD"),
 				},
 				{
 					Chr: "2", Start: 12, Stop: 13,
 					Strand: "1", Feat: "C",
-					Full: []string{"2", "12", "13", "1", "C"},
+					Opt: []byte("1\tC"),
 				},
 				{
 					Chr: "GL000209.1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"GL000209.1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "HG385_PATCH", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "MT", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"MT", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "X", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 			},
 		},
@@ -250,43 +230,33 @@ func TestNaturalSort(t *testing.T) {
 			expectedLines: []Line{
 				{
 					Chr: "chr1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr2", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrMT", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrX", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "GL000209.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "GL000226.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG385_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG987_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 			},
 		},
@@ -297,57 +267,57 @@ func TestNaturalSort(t *testing.T) {
 				{
 					Chr: "1", Start: 8, Stop: 9,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "8", "9", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "A",
-					Full: []string{"1", "10", "11", "-1", "A"},
+					Opt: []byte("-1\tA"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "10", "11", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "1", Start: 12, Stop: 13,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "12", "13", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "2", Start: 12, Stop: 13,
 					Strand: "1", Feat: "C",
-					Full: []string{"2", "12", "13", "1", "C"},
+					Opt: []byte("1\tC"),
 				},
 				{
 					Chr: "10", Start: 12, Stop: 13,
 					Strand: "1", Feat: "D",
-					Full: []string{"10", "12", "13", "1", "D"},
+					Opt: []byte("1\tD"),
 				},
 				{
 					Chr: "GL000209.1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"GL000209.1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "HG385_PATCH", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "MT", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"MT", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "X", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 			},
 		},
@@ -378,43 +348,33 @@ func TestCustomChrSort(t *testing.T) {
 			expectedLines: []Line{
 				{
 					Chr: "chr1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr2", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrX", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrMT", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "GL000209.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "GL000226.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG385_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG987_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 			},
 		},
@@ -425,57 +385,57 @@ func TestCustomChrSort(t *testing.T) {
 				{
 					Chr: "1", Start: 8, Stop: 9,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "8", "9", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "A",
-					Full: []string{"1", "10", "11", "-1", "A"},
+					Opt: []byte("-1\tA"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "10", "11", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "1", Start: 12, Stop: 13,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "12", "13", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "2", Start: 12, Stop: 13,
 					Strand: "1", Feat: "C",
-					Full: []string{"2", "12", "13", "1", "C"},
+					Opt: []byte("1\tC"),
 				},
 				{
 					Chr: "10", Start: 12, Stop: 13,
 					Strand: "1", Feat: "D",
-					Full: []string{"10", "12", "13", "1", "D"},
+					Opt: []byte("1\tD"),
 				},
 				{
 					Chr: "X", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "MT", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"MT", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "GL000209.1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"GL000209.1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "HG385_PATCH", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 			},
 		},
@@ -506,43 +466,33 @@ func TestMergeSort(t *testing.T) {
 			expectedLines: []Line{
 				{
 					Chr: "GL000209.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "GL000226.1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG385_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "HG987_PATCH", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr1", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr10", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chr2", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrMT", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 				{
 					Chr: "chrX", Start: 8, Stop: 9,
-					Full: []string{"1", "8", "9"},
 				},
 			},
 		},
@@ -553,57 +503,57 @@ func TestMergeSort(t *testing.T) {
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "A",
-					Full: []string{"1", "10", "11", "-1", "A"},
+					Opt: []byte("-1\tA"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "1", Start: 12, Stop: 13,
 					Strand: "1", Feat: "A",
-					Full: []string{"1", "12", "13", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "GL000209.1", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"GL000209.1", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "HG385_PATCH", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "MT", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"MT", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "X", Start: 10, Stop: 11,
 					Strand: "1", Feat: "A",
-					Full: []string{"X", "10", "11", "1", "A"},
+					Opt: []byte("1\tA"),
 				},
 				{
 					Chr: "1", Start: 8, Stop: 9,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "8", "9", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "1", Start: 10, Stop: 11,
 					Strand: "-1", Feat: "B",
-					Full: []string{"1", "10", "11", "-1", "B"},
+					Opt: []byte("-1\tB"),
 				},
 				{
 					Chr: "2", Start: 12, Stop: 13,
 					Strand: "1", Feat: "C",
-					Full: []string{"2", "12", "13", "1", "C"},
+					Opt: []byte("1\tC"),
 				},
 				{
 					Chr: "10", Start: 12, Stop: 13,
 					Strand: "1", Feat: "D",
-					Full: []string{"10", "12", "13", "1", "D"},
+					Opt: []byte("1\tD"),
 				},
 			},
 		},
@@ -0,0 +1,302 @@
+package bed
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Streaming presorted bed files
+//
+// The input has to be sorted by chromosome (according to --sort-type)
+// and start. Lines are padded, merged/deduplicated and written region
+// by region so that only the regions that are still open are kept in
+// memory.
+func (bf *Bedfile) Stream() error {
+	if err := bf.readChrAliasFile(); err != nil {
+		return err
+	}
+	// The fasta index is needed up front for padding and fidx sorting
+	if err := bf.readFastaIdxFile(); err != nil {
+		return err
+	}
+	return bf.openOutput(bf.stream)
+}
+
+// Stream all input files to the writer destination
+func (bf *Bedfile) stream(writer io.Writer) error {
+	sw, err := bf.newStreamWriter(writer)
+	if err != nil {
+		return err
+	}
+	for _, input := range bf.Inputs {
+		bedFile, err := openInput(input)
+		if err != nil {
+			return err
+		}
+		defer bedFile.Close()
+		bf.currentInput = input
+		if err := sw.readBed(bedFile); err != nil {
+			return fmt.Errorf("can't read bed file %s: %q", input, err)
+		}
+	}
+	bf.chrAliasWarnings()
+	return sw.close()
+}
+
+// Keeps track of the regions that are still open
+// while streaming presorted bed files
+type streamWriter struct {
+	bf          *Bedfile
+	writer      *lineWriter
+	chrCompare  func(a, b string) int
+	lineCompare func(a, b Line) int
+
+	expectedNrOfCols  int
+	padded            bool
+	headerWritten     bool
+	nrOfHeaders       int
+	hasPrev           bool
+	prev              Line
+	chrNotInLengthMap []string
+
+	// Lines sharing the start of the previous line, they are
+	// added in the same order as mergeSort() once all are read
+	sameStart []Line
+
+	// Regions not yet written, in order of their start position
+	pending []*streamRegion
+	// The region that can still be extended for each strand and feature
+	active map[string]*streamRegion
+}
+
+// Region kept in memory until it can not be extended any further
+type streamRegion struct {
+	line   Line
+	values colValues
+	done   bool
+}
+
+// Create new stream writer
+func (bf *Bedfile) newStreamWriter(writer io.Writer) (*streamWriter, error) {
+	chrCompare, err := bf.chrCompareFunc()
+	if err != nil {
+		return nil, err
+	}
+	lineCompare, err := bf.lineCompareFunc()
+	if err != nil {
+		return nil, err
+	}
+	return &streamWriter{
+		bf:          bf,
+		writer:      bf.newLineWriter(writer),
+		chrCompare:  chrCompare,
+		lineCompare: lineCompare,
+		active:      map[string]*streamRegion{},
+	}, nil
+}
+
+// Read presorted bed file and write regions as soon as they are complete
+func (sw *streamWriter) readBed(file io.Reader) error {
+	return sw.bf.scanBed(file, &sw.expectedNrOfCols, sw.handle)
+}
+
+// Verify that the line is sorted and add it to the open regions
+func (sw *streamWriter) handle(l Line, lineNr int) error {
+	// The ##contig lines of VCF files come before the first region
+	if !sw.hasPrev {
+		if err := sw.bf.VerifyVcfChrLengths(); err != nil {
+			return err
+		}
+	}
+	// Verify that the input is sorted
+	if sw.hasPrev {
+		order := cmp.Or(
+			sw.chrCompare(sw.prev.Chr, l.Chr),
+			cmp.Compare(sw.prev.Start, l.Start),
+		)
+		if order > 0 {
+			return fmt.Errorf("line %d is not sorted according to --sort-type=%s: %s",
+				lineNr, sw.bf.SortType, l.text())
+		}
+	}
+	if sw.hasPrev && (sw.prev.Chr != l.Chr || sw.prev.Start != l.Start) {
+		if err := sw.addSameStart(); err != nil {
+			return err
+		}
+	}
+	// Flush everything when we reach a new chromosome
+	if sw.hasPrev && sw.prev.Chr != l.Chr {
+		if err := sw.flush(true); err != nil {
+			return err
+		}
+	}
+	// Header lines read after the first region can not be written
+	if err := sw.writeHeader(); err != nil {
+		return err
+	}
+	sw.prev = l
+	sw.hasPrev = true
+	sw.sameStart = append(sw.sameStart, l)
+	return nil
+}
+
+// Add the lines sharing the same start ordered by their stop,
+// so that they are merged in the same order as by .MergeAndPadLines()
+func (sw *streamWriter) addSameStart() error {
+	slices.SortStableFunc(sw.sameStart, func(a, b Line) int {
+		return cmp.Compare(a.Stop, b.Stop)
+	})
+	for _, l := range sw.sameStart {
+		if err := sw.add(l); err != nil {
+			return err
+		}
+	}
+	sw.sameStart = sw.sameStart[:0]
+	return nil
+}
+
+// Pad line and add it to the open regions,
+// unless the lines have already been padded
+func (sw *streamWriter) add(l Line) error {
+	if sw.bf.HasPadding() && !sw.padded {
+		var err error
+		l, sw.chrNotInLengthMap, err = sw.bf.padAccordingToPaddingType(l, sw.chrNotInLengthMap)
+		if err != nil {
+			return err
+		}
+	}
+	if sw.bf.NoMerge {
+		return sw.addUnmerged(l)
+	}
+
+	// Merge line into the open region on the same strand and feature
+	// if they are overlapping or touching, otherwise start a new region
+	key := l.Strand + "\t" + l.Feat
+	region, ok := sw.active[key]
+	if ok && region.line.Stop+sw.bf.Overlap >= l.Start-1 {
+		sw.bf.mergeLineInto(&region.line, region.values, l)
+	} else {
+		if ok {
+			region.done = true
+		}
+		region = &streamRegion{line: l, values: sw.bf.newColValues(l)}
+		sw.active[key] = region
+		sw.pending = append(sw.pending, region)
+	}
+	// Since the following lines will not start before this line,
+	// regions ending before it can not be extended any further
+	for key, r := range sw.active {
+		if r.line.Stop+sw.bf.Overlap < l.Start-1 {
+			r.done = true
+			delete(sw.active, key)
+		}
+	}
+	return sw.flush(false)
+}
+
+// Add line when merging is turned off
+//
+// Lines are kept until a line with a later start is read, so
+// that lines with the same start can be deduplicated and sorted
+func (sw *streamWriter) addUnmerged(l Line) error {
+	for _, r := range sw.pending {
+		if l.Start > r.line.Start {
+			r.done = true
+		}
+	}
+	if err := sw.flush(false); err != nil {
+		return err
+	}
+	if sw.bf.Deduplicate {
+		joinedLine := l.text()
+		for _, r := range sw.pending {
+			if r.line.text() == joinedLine {
+				r.line.Comments = append(r.line.Comments, l.Comments...)
+				return nil
+			}
+		}
+	}
+	sw.pending = append(sw.pending, &streamRegion{line: l})
+	return nil
+}
+
+// Write the finished regions
+//
+// Regions are only written when they and all the regions starting
+// before or at the same position are done. Regions with the same
+// start are written in the order of the selected sorting type.
+// If all is true every region is written.
+func (sw *streamWriter) flush(all bool) error {
+	if err := sw.writeHeader(); err != nil {
+		return err
+	}
+	for len(sw.pending) > 0 {
+		// Find the regions sharing the first start position
+		end := 1
+		for end < len(sw.pending) && sw.pending[end].line.Start == sw.pending[0].line.Start {
+			end++
+		}
+		group := sw.pending[:end]
+		if !all && slices.ContainsFunc(group, func(r *streamRegion) bool { return !r.done }) {
+			break
+		}
+		lines := make([]Line, len(group))
+		for i, r := range group {
+			if err := sw.bf.applyColOps(&r.line, r.values); err != nil {
+				return err
+			}
+			lines[i] = r.line
+		}
+		slices.SortStableFunc(lines, sw.lineCompare)
+		for _, l := range lines {
+			if err := sw.writer.writeLine(l); err != nil {
+				return err
+			}
+		}
+		sw.pending = sw.pending[end:]
+	}
+	if all {
+		clear(sw.active)
+	}
+	return nil
+}
+
+// Write header before the first region
+func (sw *streamWriter) writeHeader() error {
+	if sw.headerWritten {
+		return nil
+	}
+	sw.headerWritten = true
+	sw.nrOfHeaders = len(sw.bf.Header)
+	for _, h := range sw.bf.Header {
+		if err := sw.writer.writeHeader(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write the remaining regions and flush the writer
+func (sw *streamWriter) close() error {
+	if err := sw.addSameStart(); err != nil {
+		return err
+	}
+	if err := sw.flush(true); err != nil {
+		return err
+	}
+	// If we have been padding print padding warnings
+	if sw.bf.HasPadding() {
+		sw.bf.paddingWarnings(sw.chrNotInLengthMap)
+	}
+	// Header lines found after the header was written can not be
+	// moved to the top, as the regions have already been written
+	if dropped := sw.bf.Header[sw.nrOfHeaders:]; len(dropped) > 0 {
+		sw.bf.warn("header lines after the first region were dropped, as they can not be moved to the top with --presorted: %q", dropped)
+	}
+	if err := sw.writer.writeComments(sw.bf.takeComments()); err != nil {
+		return err
+	}
+	return sw.writer.close()
+}
@@ -0,0 +1,313 @@
+package bed
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStreamWriter(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		bed             Bedfile
+		bedFileContents []string
+		expectedContent string
+		shouldFail      bool
+	}
+	testCases := []testCase{
+		{
+			testing: "merge chr only",
+			bed: Bedfile{
+				SortType: LexST,
+			},
+			bedFileContents: []string{
+				"1\t1\t4\t1\tA\n" +
+					"1\t5\t8\t1\tA\n" +
+					"1\t5\t8\t-1\tA\n" +
+					"1\t5\t8\t1\tB\n" +
+					"1\t6\t8\t1\tA\n" +
+					"1\t20\t30\t1\tA\n" +
+					"2\t6\t8\t1\tA\n",
+			},
+			expectedContent: "1\t1\t8\t1,-1\tA,B\n" +
+				"1\t20\t30\t1\tA\n" +
+				"2\t6\t8\t1\tA\n",
+		},
+		{
+			testing: "merge with strand and feat, regions on other strand closed later",
+			bed: Bedfile{
+				SortType:  LexST,
+				StrandCol: 4 - 1,
+				FeatCol:   5 - 1,
+			},
+			bedFileContents: []string{
+				"1\t1\t100\t-1\tA\n" +
+					"1\t5\t8\t1\tA\n" +
+					"1\t9\t12\t1\tA\n" +
+					"1\t10\t12\t1\tB\n" +
+					"1\t20\t30\t1\tA\n",
+			},
+			expectedContent: "1\t1\t100\t-1\tA\n" +
+				"1\t5\t12\t1\tA\n" +
+				"1\t10\t12\t1\tB\n" +
+				"1\t20\t30\t1\tA\n",
+		},
+		{
+			testing: "regions with the same start are sorted",
+			bed: Bedfile{
+				SortType:  LexST,
+				StrandCol: 4 - 1,
+			},
+			bedFileContents: []string{
+				"1\t5\t20\t1\tA\n" +
+					"1\t5\t8\t-1\tA\n",
+			},
+			expectedContent: "1\t5\t8\t-1\tA\n" +
+				"1\t5\t20\t1\tA\n",
+		},
+		{
+			testing: "overlap -1",
+			bed: Bedfile{
+				SortType: LexST,
+				Overlap:  -1,
+			},
+			bedFileContents: []string{
+				"1\t1\t4\n" +
+					"1\t5\t8\n" +
+					"1\t6\t9\n",
+			},
+			expectedContent: "1\t1\t4\n" +
+				"1\t5\t9\n",
+		},
+		{
+			testing: "several files and header",
+			bed: Bedfile{
+				SortType: NatST,
+			},
+			bedFileContents: []string{
+				"track something\n" +
+					"1\t1\t4\n" +
+					"2\t5\t8\n",
+				"2\t6\t9\n" +
+					"10\t1\t4\n",
+			},
+			expectedContent: "track something\n" +
+				"1\t1\t4\n" +
+				"2\t5\t9\n" +
+				"10\t1\t4\n",
+		},
+		{
+			testing: "fidx sorting",
+			bed: Bedfile{
+				SortType:    FidxST,
+				chrOrderMap: chrOrderToMap([]string{"2", "1"}),
+			},
+			bedFileContents: []string{
+				"2\t5\t8\n" +
+					"1\t1\t4\n",
+			},
+			expectedContent: "2\t5\t8\n" +
+				"1\t1\t4\n",
+		},
+		{
+			testing: "padding",
+			bed: Bedfile{
+				SortType:     LexST,
+				PaddingType:  SafePT,
+				Padding:      5,
+				FirstBase:    1,
+				chrLengthMap: testChrLengthMap,
+			},
+			bedFileContents: []string{
+				"1\t1\t4\n" +
+					"1\t12\t15\n" +
+					"1\t30\t35\n",
+			},
+			expectedContent: "1\t1\t20\n" +
+				"1\t25\t40\n",
+		},
+		{
+			testing: "no merge with deduplication",
+			bed: Bedfile{
+				SortType:    LexST,
+				NoMerge:     true,
+				Deduplicate: true,
+			},
+			bedFileContents: []string{
+				"1\t1\t8\n" +
+					"1\t1\t4\n" +
+					"1\t1\t8\n" +
+					"1\t5\t8\n" +
+					"1\t5\t8\n",
+			},
+			expectedContent: "1\t1\t4\n" +
+				"1\t1\t8\n" +
+				"1\t5\t8\n",
+		},
+		{
+			testing: "unsorted start",
+			bed: Bedfile{
+				SortType: LexST,
+			},
+			bedFileContents: []string{
+				"1\t5\t8\n" +
+					"1\t1\t4\n",
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "unsorted chromosomes across files",
+			bed: Bedfile{
+				SortType: LexST,
+			},
+			bedFileContents: []string{
+				"2\t5\t8\n",
+				"1\t1\t4\n",
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "chromosome not in fasta index, safe padding",
+			bed: Bedfile{
+				SortType:     LexST,
+				PaddingType:  SafePT,
+				Padding:      5,
+				chrLengthMap: testChrLengthMap,
+			},
+			bedFileContents: []string{
+				"unknown\t5\t8\n",
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "merge with column operations",
+			bed: Bedfile{
+				SortType: LexST,
+				colOps:   map[int]string{3: "sum", 4: "distinct"},
+			},
+			bedFileContents: []string{
+				"1\t1\t4\t1\tA\n" +
+					"1\t5\t8\t1.5\tA\n" +
+					"1\t5\t8\t-1\tB\n" +
+					"1\t20\t30\t1\tA\n",
+			},
+			expectedContent: "1\t1\t8\t1.5\tA,B\n" +
+				"1\t20\t30\t1\tA\n",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var output bytes.Buffer
+			sw, err := tc.bed.newStreamWriter(&output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, content := range tc.bedFileContents {
+				if err = sw.readBed(strings.NewReader(content)); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = sw.close()
+			}
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail && tc.expectedContent != output.String() {
+				t.Error("expectedContent vs output:\n",
+					tc.expectedContent, "\n!=\n", output.String())
+			}
+		})
+	}
+}
+
+func TestStreamHeaderAfterFirstRegion(t *testing.T) {
+	t.Parallel()
+	var output, warnings bytes.Buffer
+	bf := Bedfile{SortType: LexST, HeaderPolicy: FirstHP, CommentPolicy: KeepCP}
+	bf.SetWarningWriter(&warnings)
+	sw, err := bf.newStreamWriter(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "track name=a\n" +
+		"1\t1\t4\n" +
+		"track name=b\n" +
+		"#c\n" +
+		"1\t10\t20\n"
+	if err := sw.readBed(strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.close(); err != nil {
+		t.Fatal(err)
+	}
+	expectedContent := "track name=a\n" +
+		"1\t1\t4\n" +
+		"#c\n" +
+		"1\t10\t20\n"
+	if expectedContent != output.String() {
+		t.Error("expectedContent vs output:\n",
+			expectedContent, "\n!=\n", output.String())
+	}
+	expectedWarnings := "warning: header lines after the first region were dropped, as they can not be moved to the top with --presorted: [\"track name=b\"]\n"
+	if expectedWarnings != warnings.String() {
+		t.Error("expectedWarnings vs warnings:\n",
+			expectedWarnings, "\n!=\n", warnings.String())
+	}
+}
+
+func TestStreamMatchesMergeAndPadLines(t *testing.T) {
+	t.Parallel()
+	content := "1\t999\t1200\t-\tA\n" +
+		"1\t999\t1100\t-\tB\n" +
+		"1\t1000\t1050\t-\tC\n" +
+		"1\t2000\t2100\t+\tD\n" +
+		"1\t2000\t2050\t-\tE\n"
+	for _, op := range []string{"first", "last", "distinct"} {
+		op := op
+		t.Run(op, func(t *testing.T) {
+			t.Parallel()
+			newBedfile := func() Bedfile {
+				return Bedfile{
+					SortType:  LexST,
+					StrandCol: 4 - 1,
+					colOps:    map[int]string{5 - 1: op},
+				}
+			}
+			inMemory := newBedfile()
+			if err := inMemory.readBed(strings.NewReader(content)); err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemory.MergeAndPadLines(); err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemory.Sort(); err != nil {
+				t.Fatal(err)
+			}
+			var expectedContent bytes.Buffer
+			if err := inMemory.WriteBedTo(&expectedContent); err != nil {
+				t.Fatal(err)
+			}
+
+			streamed := newBedfile()
+			var output bytes.Buffer
+			sw, err := streamed.newStreamWriter(&output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sw.readBed(strings.NewReader(content)); err != nil {
+				t.Fatal(err)
+			}
+			if err := sw.close(); err != nil {
+				t.Fatal(err)
+			}
+			if expectedContent.String() != output.String() {
+				t.Error("expectedContent vs output:\n",
+					expectedContent.String(), "\n!=\n", output.String())
+			}
+		})
+	}
+}
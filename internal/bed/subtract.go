@@ -0,0 +1,88 @@
+package bed
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+type Subtract struct {
+	B           []string `short:"b" required:"" help:"Bed file path(s) with the regions to remove from the inputs. If more than one is provided the files will be joined as if they were one file. If --strand-col is set these files must have the strand in the same column"`
+	MinOverlap  float64  `env:"MIN_OVERLAP" group:"subtract" default:"0" help:"Minimum overlap required for a region to be removed, given as a fraction of the input region (0-1). If 0 any overlap is enough"`
+	RemoveWhole bool     `env:"REMOVE_WHOLE" group:"subtract" help:"Remove the whole input region if it overlaps, instead of only removing the overlapping part"`
+}
+
+// Verifies and handles Subtract input
+func (st *Subtract) VerifyAndHandle() error {
+	if st.MinOverlap < 0 || st.MinOverlap > 1 {
+		return fmt.Errorf("--min-overlap must be between 0 and 1: %g", st.MinOverlap)
+	}
+	for i, b := range st.B {
+		st.B[i] = filepath.Clean(b)
+	}
+	return nil
+}
+
+// Subtract the regions in the --b bed files from the lines
+func (bf *Bedfile) Subtract(st Subtract) error {
+	other, err := bf.readOther(st.B)
+	if err != nil {
+		return err
+	}
+	bf.Lines = bf.subtractLines(other.Lines, st)
+	return nil
+}
+
+// Subtract other lines from the lines
+//
+// Each line is split into the parts that are not overlapped by the
+// other lines, keeping the optional columns of the line. Only other
+// lines overlapping at least --min-overlap of the line are removed,
+// and if --strand-col is set only lines on the same strand.
+func (bf *Bedfile) subtractLines(others []Line, st Subtract) []Line {
+	index := newRegionIndex(others)
+	var subtracted []Line
+	for _, l := range bf.Lines {
+		overlapping := slices.DeleteFunc(index.overlapping(l.Chr, l.Start, l.Stop), func(o Line) bool {
+			return o.Strand != l.Strand || overlapFraction(l, o) < st.MinOverlap
+		})
+		if len(overlapping) == 0 {
+			subtracted = append(subtracted, l)
+			continue
+		}
+		if st.RemoveWhole {
+			continue
+		}
+		// The overlapping lines are sorted by start, so the parts
+		// between them are the parts that are left
+		start := l.Start
+		for _, o := range overlapping {
+			if o.Start > start {
+				subtracted = append(subtracted, linePart(l, start, o.Start))
+			}
+			start = max(start, o.Stop)
+		}
+		if start < l.Stop {
+			subtracted = append(subtracted, linePart(l, start, l.Stop))
+		}
+	}
+	return subtracted
+}
+
+// Fraction of the line overlapped by the other line,
+// lines with equal start and stop are either fully or not overlapped
+func overlapFraction(l, other Line) float64 {
+	if l.Stop == l.Start {
+		return 1
+	}
+	overlap := min(l.Stop, other.Stop) - max(l.Start, other.Start)
+	return float64(overlap) / float64(l.Stop-l.Start)
+}
+
+// Returns a copy of the line with new start and stop
+func linePart(l Line, start, stop int) Line {
+	part := l
+	part.Start = start
+	part.Stop = stop
+	return part
+}
@@ -0,0 +1,196 @@
+package bed
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+var testSubtractA = []Line{
+	{
+		Chr: "1", Start: 0, Stop: 100,
+		Strand: "+",
+		Opt:    []byte("+\tA"),
+	},
+	{
+		Chr: "2", Start: 10, Stop: 20,
+		Strand: "-",
+		Opt:    []byte("-\tB"),
+	},
+}
+
+var testSubtractB = []Line{
+	{
+		Chr: "1", Start: 10, Stop: 20,
+		Strand: "+",
+		Opt:    []byte("+"),
+	},
+	{
+		Chr: "1", Start: 15, Stop: 30,
+		Strand: "-",
+		Opt:    []byte("-"),
+	},
+	{
+		Chr: "1", Start: 90, Stop: 120,
+		Strand: "+",
+		Opt:    []byte("+"),
+	},
+	{
+		Chr: "2", Start: 0, Stop: 10,
+		Strand: "-",
+		Opt:    []byte("-"),
+	},
+}
+
+func TestSubtractLines(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		lines         []Line
+		others        []Line
+		subtract      Subtract
+		expectedLines []Line
+	}
+	testCases := []testCase{
+		{
+			testing: "split regions",
+			lines:   withoutStrand(testSubtractA),
+			others:  withoutStrand(testSubtractB),
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 0, Stop: 10,
+					Opt: []byte("+\tA"),
+				},
+				{
+					Chr: "1", Start: 30, Stop: 90,
+					Opt: []byte("+\tA"),
+				},
+				{
+					Chr: "2", Start: 10, Stop: 20,
+					Opt: []byte("-\tB"),
+				},
+			},
+		},
+		{
+			testing: "split regions with strand",
+			lines:   deepCopyLines(testSubtractA),
+			others:  deepCopyLines(testSubtractB),
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 0, Stop: 10,
+					Strand: "+",
+					Opt:    []byte("+\tA"),
+				},
+				{
+					Chr: "1", Start: 20, Stop: 90,
+					Strand: "+",
+					Opt:    []byte("+\tA"),
+				},
+				{
+					Chr: "2", Start: 10, Stop: 20,
+					Strand: "-",
+					Opt:    []byte("-\tB"),
+				},
+			},
+		},
+		{
+			testing: "minimum overlap",
+			lines:   withoutStrand(testSubtractA),
+			others:  withoutStrand(testSubtractB),
+			subtract: Subtract{
+				MinOverlap: 0.15,
+			},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 0, Stop: 15,
+					Opt: []byte("+\tA"),
+				},
+				{
+					Chr: "1", Start: 30, Stop: 100,
+					Opt: []byte("+\tA"),
+				},
+				{
+					Chr: "2", Start: 10, Stop: 20,
+					Opt: []byte("-\tB"),
+				},
+			},
+		},
+		{
+			testing: "remove whole",
+			lines:   withoutStrand(testSubtractA),
+			others:  withoutStrand(testSubtractB),
+			subtract: Subtract{
+				RemoveWhole: true,
+			},
+			expectedLines: []Line{
+				{
+					Chr: "2", Start: 10, Stop: 20,
+					Opt: []byte("-\tB"),
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			bf := Bedfile{Lines: tc.lines}
+			lines := bf.subtractLines(tc.others, tc.subtract)
+			if diff := deep.Equal(tc.expectedLines, lines); diff != nil {
+				t.Error("expected VS received lines", diff)
+			}
+		})
+	}
+}
+
+func TestSubtractVerifyAndHandle(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing          string
+		subtract         Subtract
+		expectedSubtract Subtract
+		shouldFail       bool
+	}
+	testCases := []testCase{
+		{
+			testing: "clean paths",
+			subtract: Subtract{
+				B:          []string{"/some/../path/test.bed"},
+				MinOverlap: 0.5,
+			},
+			expectedSubtract: Subtract{
+				B:          []string{"/path/test.bed"},
+				MinOverlap: 0.5,
+			},
+		},
+		{
+			testing: "min overlap above 1",
+			subtract: Subtract{
+				MinOverlap: 1.5,
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "negative min overlap",
+			subtract: Subtract{
+				MinOverlap: -0.5,
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.subtract.VerifyAndHandle()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedSubtract, tc.subtract); diff != nil {
+					t.Error("expected VS received subtract", diff)
+				}
+			}
+		})
+	}
+}
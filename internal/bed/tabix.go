@@ -0,0 +1,308 @@
+package bed
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Tabix index constants
+const (
+	// Size of the smallest bin and the linear index windows (16 kbp)
+	tabixMinShift = 14
+	// Number of bin levels in tbi files, covering 2^29 bp (512 Mbp)
+	tabixLevels = 5
+	// Tabix preset for bed files: UCSC format (0-based, half-open)
+	// with chr, start and stop in column 1, 2 and 3 and meta lines
+	// starting with #
+	tabixFormatUCSC = 0x10000
+	tabixColSeq     = 1
+	tabixColBeg     = 2
+	tabixColEnd     = 3
+	tabixMeta       = '#'
+)
+
+// Builds a tabix (.tbi) or coordinate sorted index (.csi)
+// for a BGZF compressed bed file while it is written
+//
+// Bins are recorded by their level counted from the bottom, so that
+// the number of levels can be decided when all regions have been
+// seen. If a region ends beyond 512 Mbp a csi index is created, as
+// tbi indexes can not hold larger coordinates.
+type tabixIndexer struct {
+	skip   int
+	names  []string
+	refs   map[string]*tabixRef
+	maxEnd int
+}
+
+// Index of a single chromosome
+type tabixRef struct {
+	bins      map[tabixBinKey][]tabixChunk
+	linear    []uint64
+	lastStart int
+	firstOff  uint64
+	lastOff   uint64
+	nrRecords uint64
+}
+
+// Bin identified by level counted from the bottom
+// and the position of the bin within the level
+type tabixBinKey struct {
+	level int
+	pos   int
+}
+
+// Chunk of the file given as virtual offsets
+type tabixChunk struct {
+	beg uint64
+	end uint64
+}
+
+// Unset linear index window
+const tabixUnset = ^uint64(0)
+
+// Create new indexer, skip is the number of header lines
+func newTabixIndexer(skip int) *tabixIndexer {
+	return &tabixIndexer{
+		skip: skip,
+		refs: map[string]*tabixRef{},
+	}
+}
+
+// Add a region written between the virtual offsets beg and end
+//
+// The regions have to be sorted by coordinates, meaning that all
+// regions on a chromosome are written together and that their start
+// positions are increasing
+func (ti *tabixIndexer) add(chr string, start, stop int, beg, end uint64) error {
+	ref, ok := ti.refs[chr]
+	if !ok {
+		ref = &tabixRef{bins: map[tabixBinKey][]tabixChunk{}, firstOff: beg}
+		ti.refs[chr] = ref
+		ti.names = append(ti.names, chr)
+	} else if ti.names[len(ti.names)-1] != chr {
+		return fmt.Errorf("can't index output: regions on chromosome %s are not written together, the output must be sorted by coordinates", chr)
+	}
+	if start < ref.lastStart {
+		return fmt.Errorf("can't index output: start %d on chromosome %s comes after start %d, the output must be sorted by coordinates", start, chr, ref.lastStart)
+	}
+	ref.lastStart = start
+	// Regions with equal start and stop are indexed as one base
+	if stop <= start {
+		stop = start + 1
+	}
+	ti.maxEnd = max(ti.maxEnd, stop)
+
+	// Add to bin, extending the last chunk if it ends where this region begins
+	key := regionToBinKey(start, stop)
+	chunks := ref.bins[key]
+	if n := len(chunks); n > 0 && chunks[n-1].end == beg {
+		chunks[n-1].end = end
+	} else {
+		chunks = append(chunks, tabixChunk{beg: beg, end: end})
+	}
+	ref.bins[key] = chunks
+
+	// Add to linear index
+	for w := start >> tabixMinShift; w <= (stop-1)>>tabixMinShift; w++ {
+		for len(ref.linear) <= w {
+			ref.linear = append(ref.linear, tabixUnset)
+		}
+		if ref.linear[w] == tabixUnset {
+			ref.linear[w] = beg
+		}
+	}
+	ref.lastOff = end
+	ref.nrRecords++
+	return nil
+}
+
+// Find the smallest bin the region fits into
+func regionToBinKey(start, stop int) tabixBinKey {
+	level := 0
+	for shift := tabixMinShift; start>>shift != (stop-1)>>shift; shift += 3 {
+		level++
+	}
+	return tabixBinKey{level: level, pos: start >> (tabixMinShift + 3*level)}
+}
+
+// Returns true if a csi index is needed
+func (ti *tabixIndexer) isCSI() bool {
+	return ti.maxEnd > 1<<(tabixMinShift+3*tabixLevels)
+}
+
+// The file extension of the index
+func (ti *tabixIndexer) extension() string {
+	if ti.isCSI() {
+		return ".csi"
+	}
+	return ".tbi"
+}
+
+// Number of bin levels needed to cover all regions
+func (ti *tabixIndexer) levels() int {
+	levels := tabixLevels
+	for ti.maxEnd > 1<<(tabixMinShift+3*levels) {
+		levels++
+	}
+	return levels
+}
+
+// Index of the first bin on a level counted from the top
+func firstBinOnLevel(level int) int {
+	return ((1 << (3 * level)) - 1) / 7
+}
+
+// Write the BGZF compressed index
+func (ti *tabixIndexer) write(writer io.Writer) error {
+	bw := newBgzfWriter(writer)
+	var err error
+	if ti.isCSI() {
+		err = ti.writeCSI(bw)
+	} else {
+		err = ti.writeTBI(bw)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Close()
+}
+
+// Tabix configuration for bed files, shared by tbi and csi
+func (ti *tabixIndexer) config() []any {
+	var names []byte
+	for _, name := range ti.names {
+		names = append(names, name...)
+		names = append(names, 0)
+	}
+	return []any{
+		int32(tabixFormatUCSC), int32(tabixColSeq), int32(tabixColBeg), int32(tabixColEnd),
+		int32(tabixMeta), int32(ti.skip), int32(len(names)), names,
+	}
+}
+
+// Write index in the tbi format
+func (ti *tabixIndexer) writeTBI(writer io.Writer) error {
+	fields := []any{[]byte("TBI\x01"), int32(len(ti.names))}
+	fields = append(fields, ti.config()...)
+	for _, name := range ti.names {
+		ref := ti.refs[name]
+		bins := ref.finishBins(tabixLevels)
+		fields = append(fields, int32(len(bins)))
+		for _, b := range bins {
+			fields = append(fields, b.bin, int32(len(b.chunks)), b.chunks)
+		}
+		linear := ref.finishLinear()
+		fields = append(fields, int32(len(linear)), linear)
+	}
+	return writeLittleEndian(writer, fields)
+}
+
+// Write index in the csi format
+func (ti *tabixIndexer) writeCSI(writer io.Writer) error {
+	levels := ti.levels()
+	var auxSize int
+	config := ti.config()
+	for _, field := range config {
+		auxSize += binary.Size(field)
+	}
+	fields := []any{[]byte("CSI\x01"), int32(tabixMinShift), int32(levels), int32(auxSize)}
+	fields = append(fields, config...)
+	fields = append(fields, int32(len(ti.names)))
+	for _, name := range ti.names {
+		ref := ti.refs[name]
+		bins := ref.finishBins(levels)
+		linear := ref.finishLinear()
+		fields = append(fields, int32(len(bins)))
+		for _, b := range bins {
+			fields = append(fields, b.bin, b.loffset(linear, levels), int32(len(b.chunks)), b.chunks)
+		}
+	}
+	return writeLittleEndian(writer, fields)
+}
+
+// Finished bin with its number and chunks
+type tabixBin struct {
+	bin    uint32
+	level  int
+	pos    int
+	chunks []tabixChunk
+}
+
+// Number bins according to the number of levels, merge chunks within
+// the same BGZF block and add the pseudo bin holding the offsets and
+// number of regions of the chromosome
+func (ref *tabixRef) finishBins(levels int) []tabixBin {
+	var bins []tabixBin
+	for key, chunks := range ref.bins {
+		level := levels - key.level
+		var merged []tabixChunk
+		for _, c := range chunks {
+			if n := len(merged); n > 0 && merged[n-1].end>>16 == c.beg>>16 {
+				merged[n-1].end = c.end
+				continue
+			}
+			merged = append(merged, c)
+		}
+		bins = append(bins, tabixBin{
+			bin:    uint32(firstBinOnLevel(level) + key.pos),
+			level:  level,
+			pos:    key.pos,
+			chunks: merged,
+		})
+	}
+	slices.SortFunc(bins, func(a, b tabixBin) int {
+		return cmp.Compare(a.bin, b.bin)
+	})
+	bins = append(bins, tabixBin{
+		bin:   uint32(firstBinOnLevel(levels+1) + 1),
+		level: -1,
+		chunks: []tabixChunk{
+			{beg: ref.firstOff, end: ref.lastOff},
+			{beg: ref.nrRecords, end: 0},
+		},
+	})
+	return bins
+}
+
+// Fill the windows without regions with the offset of the previous window
+func (ref *tabixRef) finishLinear() []uint64 {
+	linear := slices.Clone(ref.linear)
+	for i := range linear {
+		if linear[i] != tabixUnset {
+			continue
+		}
+		if i == 0 {
+			linear[i] = ref.firstOff
+		} else {
+			linear[i] = linear[i-1]
+		}
+	}
+	return linear
+}
+
+// Smallest offset of regions overlapping the start of the bin,
+// used by csi indexes instead of the linear index
+func (b tabixBin) loffset(linear []uint64, levels int) uint64 {
+	if b.level < 0 {
+		return 0
+	}
+	window := b.pos << (3 * (levels - b.level))
+	if window < len(linear) {
+		return linear[window]
+	}
+	return 0
+}
+
+// Write fields in little endian byte order
+func writeLittleEndian(writer io.Writer, fields []any) error {
+	for _, field := range fields {
+		if err := binary.Write(writer, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
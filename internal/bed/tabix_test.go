@@ -0,0 +1,254 @@
+package bed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// Bin calculation from the SAM specification
+func reg2bin(beg, end int) int {
+	end--
+	if beg>>14 == end>>14 {
+		return ((1<<15)-1)/7 + (beg >> 14)
+	}
+	if beg>>17 == end>>17 {
+		return ((1<<12)-1)/7 + (beg >> 17)
+	}
+	if beg>>20 == end>>20 {
+		return ((1<<9)-1)/7 + (beg >> 20)
+	}
+	if beg>>23 == end>>23 {
+		return ((1<<6)-1)/7 + (beg >> 23)
+	}
+	if beg>>26 == end>>26 {
+		return ((1<<3)-1)/7 + (beg >> 26)
+	}
+	return 0
+}
+
+func TestRegionToBinKey(t *testing.T) {
+	t.Parallel()
+	regions := [][2]int{
+		{0, 1}, {0, 16384}, {0, 16385}, {16383, 16385}, {100000, 100100},
+		{131071, 131073}, {1000000, 3000000}, {60000000, 70000000},
+		{0, 536870912}, {536870000, 536870912},
+	}
+	for _, region := range regions {
+		key := regionToBinKey(region[0], region[1])
+		bin := firstBinOnLevel(tabixLevels-key.level) + key.pos
+		if expected := reg2bin(region[0], region[1]); bin != expected {
+			t.Errorf("expected bin %d for region %v got %d", expected, region, bin)
+		}
+	}
+}
+
+func TestTabixIndexerAdd(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		regions    []Line
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "sorted",
+			regions: []Line{
+				{Chr: "chr1", Start: 10, Stop: 100},
+				{Chr: "chr1", Start: 10, Stop: 50},
+				{Chr: "chr1", Start: 20, Stop: 20},
+				{Chr: "chr2", Start: 5, Stop: 10},
+			},
+		},
+		{
+			testing: "unsorted start",
+			regions: []Line{
+				{Chr: "chr1", Start: 20, Stop: 100},
+				{Chr: "chr1", Start: 10, Stop: 50},
+			},
+			shouldFail: true,
+		},
+		{
+			testing: "chromosome not written together",
+			regions: []Line{
+				{Chr: "chr1", Start: 10, Stop: 100},
+				{Chr: "Chr1", Start: 20, Stop: 50},
+				{Chr: "chr1", Start: 30, Stop: 50},
+			},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			var err error
+			ti := newTabixIndexer(0)
+			for i, l := range tc.regions {
+				if err = ti.add(l.Chr, l.Start, l.Stop, uint64(i*10), uint64(i*10+10)); err != nil {
+					break
+				}
+			}
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestTabixIndexerWrite(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing           string
+		regions           []Line
+		expectedExtension string
+		expectedMagic     string
+	}
+	testCases := []testCase{
+		{
+			testing: "tbi",
+			regions: []Line{
+				{Chr: "chr1", Start: 10, Stop: 100},
+				{Chr: "chr2", Start: 5, Stop: 10},
+			},
+			expectedExtension: ".tbi",
+			expectedMagic:     "TBI\x01",
+		},
+		{
+			testing: "csi for regions beyond 512 Mbp",
+			regions: []Line{
+				{Chr: "chr1", Start: 10, Stop: 100},
+				{Chr: "chr1", Start: 600000000, Stop: 600000100},
+			},
+			expectedExtension: ".csi",
+			expectedMagic:     "CSI\x01",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			ti := newTabixIndexer(1)
+			for i, l := range tc.regions {
+				if err := ti.add(l.Chr, l.Start, l.Stop, uint64(i*10), uint64(i*10+10)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if ti.extension() != tc.expectedExtension {
+				t.Errorf("expected extension %s got %s", tc.expectedExtension, ti.extension())
+			}
+			var compressed bytes.Buffer
+			if err := ti.write(&compressed); err != nil {
+				t.Fatal(err)
+			}
+			reader, err := gzip.NewReader(&compressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			index, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(index[:4]) != tc.expectedMagic {
+				t.Errorf("expected magic %q got %q", tc.expectedMagic, index[:4])
+			}
+			// Verify the tabix configuration and the chromosome names
+			config := index[8:]
+			if tc.expectedMagic == "CSI\x01" {
+				config = index[16:]
+			}
+			expectedConfig := []int32{tabixFormatUCSC, tabixColSeq, tabixColBeg, tabixColEnd, tabixMeta, 1}
+			for i, expected := range expectedConfig {
+				if value := int32(binary.LittleEndian.Uint32(config[i*4:])); value != expected {
+					t.Errorf("expected config value %d at position %d got %d", expected, i, value)
+				}
+			}
+			var names []byte
+			for _, name := range ti.names {
+				names = append(names, name...)
+				names = append(names, 0)
+			}
+			if !bytes.Contains(config, names) {
+				t.Errorf("expected chromosome names %q in index", names)
+			}
+		})
+	}
+}
+
+func TestTabixIndexSkip(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing   string
+		presorted bool
+	}
+	testCases := []testCase{
+		{
+			testing: "sorted in memory",
+		},
+		{
+			testing:   "presorted",
+			presorted: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			input := filepath.Join(dir, "input.bed")
+			content := "browser position chr1:1-100\ntrack name=test\nchr1\t1\t4\nchr1\t5\t8\n"
+			if err := os.WriteFile(input, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			bf := Bedfile{
+				Inputs:    []string{input},
+				Output:    filepath.Join(dir, "output.bed.gz"),
+				Presorted: tc.presorted,
+				Bgzip:     true,
+				Tabix:     true,
+				SortType:  LexST,
+				Threads:   1,
+			}
+			if err := bf.VerifyAndHandle(); err != nil {
+				t.Fatal(err)
+			}
+			if tc.presorted {
+				if err := bf.Stream(); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				if err := bf.Read(); err != nil {
+					t.Fatal(err)
+				}
+				if err := bf.Write(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			file, err := os.Open(bf.Output + ".tbi")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			reader, err := gzip.NewReader(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			index, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The number of lines to skip follows the magic,
+			// the number of chromosomes and five config values
+			skip := int32(binary.LittleEndian.Uint32(index[28:]))
+			if diff := deep.Equal(int32(2), skip); diff != nil {
+				t.Error("expected VS received header lines to skip", diff)
+			}
+		})
+	}
+}
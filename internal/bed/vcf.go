@@ -0,0 +1,148 @@
+package bed
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Input formats
+var VcfIF = "vcf" // VCF file, each record is converted to a region spanning the REF allele
+
+// VCF constants
+const (
+	chromVIdx = 0
+	posVIdx   = 1
+	idVIdx    = 2
+	refVIdx   = 3
+	infoVIdx  = 7
+	minVCols  = 8
+)
+
+var contigPattern = regexp.MustCompile(`^##contig=<(.*)>$`)
+
+// Verify VCF options
+func (bf Bedfile) verifyVcf() error {
+	if bf.InputFormat != VcfIF && len(bf.InfoField) > 0 {
+		return fmt.Errorf("--info-field must be used together with --input-format=%s", VcfIF)
+	}
+	return nil
+}
+
+// Verify that the chromosome lengths and order needed for padding and
+// fasta index sorting were found in the ##contig lines when --fasta-idx
+// is not set, must be called after the header of the VCF files is read
+func (bf Bedfile) VerifyVcfChrLengths() error {
+	if bf.InputFormat != VcfIF || bf.FastaIdx != "" {
+		return nil
+	}
+	if bf.HasPadding() && bf.PaddingType != ForcePT && len(bf.chrLengthMap) == 0 {
+		return fmt.Errorf("--padding-type=%s must be used together with --fasta-idx or VCF files with ##contig lines", bf.PaddingType)
+	}
+	if bf.SortType == FidxST && len(bf.chrOrderMap) == 0 {
+		return fmt.Errorf("--sort-type=%s must be used together with --fasta-idx or VCF files with ##contig lines", bf.SortType)
+	}
+	return nil
+}
+
+// Convert a VCF line to a bed line
+//
+// The region spans the REF allele, converted from the 1-based POS to
+// 0-based half-open coordinates, and the bed line contains chr, start,
+// stop and ID followed by the INFO fields given by --info-field.
+// Returns false for meta-information and header lines. The contig
+// lengths in the ##contig lines are used as chromosome lengths
+// if --fasta-idx is not set.
+func (bf *Bedfile) vcfToBed(lineText string, lineNr int) (string, bool, error) {
+	if strings.HasPrefix(lineText, "#") {
+		if bf.FastaIdx == "" {
+			if err := bf.readVcfContig(lineText, lineNr); err != nil {
+				return "", false, err
+			}
+		}
+		return "", false, nil
+	}
+	if lineText == "" {
+		return "", false, nil
+	}
+	cols := strings.Split(lineText, "\t")
+	if len(cols) < minVCols {
+		return "", false, fmt.Errorf("expected at least %d columns on line %d got %d: %s",
+			minVCols, lineNr, len(cols), lineText)
+	}
+	pos, err := strconv.Atoi(cols[posVIdx])
+	if err != nil {
+		return "", false, fmt.Errorf("non-int position on line %d: %s", lineNr, cols[posVIdx])
+	}
+	start := pos - 1
+	bedCols := []string{cols[chromVIdx], strconv.Itoa(start), strconv.Itoa(start + len(cols[refVIdx])), cols[idVIdx]}
+	if len(bf.InfoField) > 0 {
+		info := parseInfo(cols[infoVIdx])
+		for _, field := range bf.InfoField {
+			value, ok := info[field]
+			if !ok {
+				value = "."
+			}
+			bedCols = append(bedCols, value)
+		}
+	}
+	return strings.Join(bedCols, "\t"), true, nil
+}
+
+// Read the length of a contig from a ##contig line
+//
+// Other meta-information lines and contigs without a length are ignored
+func (bf *Bedfile) readVcfContig(lineText string, lineNr int) error {
+	match := contigPattern.FindStringSubmatch(lineText)
+	if match == nil {
+		return nil
+	}
+	var id, length string
+	for _, field := range strings.Split(match[1], ",") {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "ID":
+			id = value
+		case "length":
+			length = value
+		}
+	}
+	if id == "" || length == "" {
+		return nil
+	}
+	size, err := strconv.Atoi(length)
+	if err != nil {
+		return fmt.Errorf("non-int length for contig %s on line %d: %s", id, lineNr, length)
+	}
+	chr := bf.resolveChr(id)
+	if bf.chrLengthMap == nil {
+		bf.chrLengthMap = map[string]int{}
+	}
+	if _, ok := bf.chrLengthMap[chr]; !ok && bf.SortType == FidxST {
+		if bf.chrOrderMap == nil {
+			bf.chrOrderMap = map[string]int{}
+		}
+		bf.chrOrderMap[strings.ToLower(chr)] = len(bf.chrOrderMap) + 1
+	}
+	bf.chrLengthMap[chr] = size
+	return nil
+}
+
+// Parse the INFO column of a VCF line
+//
+// Flags are given the value 1
+func parseInfo(col string) map[string]string {
+	info := map[string]string{}
+	if col == "." {
+		return info
+	}
+	for _, field := range strings.Split(col, ";") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			value = "1"
+		}
+		info[key] = value
+	}
+	return info
+}
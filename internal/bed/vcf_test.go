@@ -0,0 +1,200 @@
+package bed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+var testVcf = strings.Join([]string{
+	"##fileformat=VCFv4.2",
+	"##contig=<ID=2,length=200>",
+	"##contig=<ID=1,length=100,assembly=test>",
+	"##contig=<ID=3>",
+	"##INFO=<ID=GENE,Number=1,Type=String,Description=\"Gene name\">",
+	"#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO",
+	"1\t10\trs1\tC\tT\t.\tPASS\tGENE=A;AF=0.2;HOTSPOT",
+	"1\t20\t.\tCTG\tC\t.\tPASS\t.",
+	"2\t5\trs3\tA\tT,G\t.\tPASS\tGENE=B",
+}, "\n")
+
+func TestReadVcf(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing              string
+		bf                   Bedfile
+		content              string
+		expectedLines        []Line
+		expectedChrLengthMap map[string]int
+		expectedChrOrderMap  map[string]int
+		shouldFail           bool
+	}
+	testCases := []testCase{
+		{
+			testing: "vcf without info fields",
+			bf:      Bedfile{InputFormat: VcfIF, SortType: LexST},
+			content: testVcf,
+			expectedLines: []Line{
+				{Chr: "1", Start: 9, Stop: 10, Opt: []byte("rs1")},
+				{Chr: "1", Start: 19, Stop: 22, Opt: []byte(".")},
+				{Chr: "2", Start: 4, Stop: 5, Opt: []byte("rs3")},
+			},
+			expectedChrLengthMap: map[string]int{"1": 100, "2": 200},
+		},
+		{
+			testing: "vcf with info fields and fasta index sorting",
+			bf:      Bedfile{InputFormat: VcfIF, SortType: FidxST, InfoField: []string{"GENE", "HOTSPOT", "AF"}},
+			content: testVcf,
+			expectedLines: []Line{
+				{Chr: "1", Start: 9, Stop: 10, Opt: []byte("rs1\tA\t1\t0.2")},
+				{Chr: "1", Start: 19, Stop: 22, Opt: []byte(".\t.\t.\t.")},
+				{Chr: "2", Start: 4, Stop: 5, Opt: []byte("rs3\tB\t.\t.")},
+			},
+			expectedChrLengthMap: map[string]int{"1": 100, "2": 200},
+			expectedChrOrderMap:  map[string]int{"2": 1, "1": 2},
+		},
+		{
+			testing: "contig lines are ignored when the fasta index is set",
+			bf:      Bedfile{InputFormat: VcfIF, SortType: LexST, FastaIdx: "test.fasta.fai"},
+			content: testVcf,
+			expectedLines: []Line{
+				{Chr: "1", Start: 9, Stop: 10, Opt: []byte("rs1")},
+				{Chr: "1", Start: 19, Stop: 22, Opt: []byte(".")},
+				{Chr: "2", Start: 4, Stop: 5, Opt: []byte("rs3")},
+			},
+		},
+		{
+			testing:    "non-int contig length",
+			bf:         Bedfile{InputFormat: VcfIF, SortType: LexST},
+			content:    "##contig=<ID=1,length=x>",
+			shouldFail: true,
+		},
+		{
+			testing:    "non-int position",
+			bf:         Bedfile{InputFormat: VcfIF, SortType: LexST},
+			content:    "1\tx\trs1\tC\tT\t.\tPASS\t.",
+			shouldFail: true,
+		},
+		{
+			testing:    "too few columns",
+			bf:         Bedfile{InputFormat: VcfIF, SortType: LexST},
+			content:    "1\t10\trs1\tC\tT\t.\tPASS",
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bf.readBed(strings.NewReader(tc.content))
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedLines, tc.bf.Lines); diff != nil {
+					t.Error("expected VS received lines", diff)
+				}
+				if diff := deep.Equal(tc.expectedChrLengthMap, tc.bf.chrLengthMap); diff != nil {
+					t.Error("expected VS received chr length map", diff)
+				}
+				if diff := deep.Equal(tc.expectedChrOrderMap, tc.bf.chrOrderMap); diff != nil {
+					t.Error("expected VS received chr order map", diff)
+				}
+				if diff := deep.Equal([]string(nil), tc.bf.Header); diff != nil {
+					t.Error("expected VS received header", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyVcf(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		bf         Bedfile
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "info fields with vcf input",
+			bf:      Bedfile{InputFormat: VcfIF, InfoField: []string{"AF"}},
+		},
+		{
+			testing:    "info fields with bed input",
+			bf:         Bedfile{InputFormat: BedIF, InfoField: []string{"AF"}},
+			shouldFail: true,
+		},
+		{
+			testing: "padding without fasta index with vcf input",
+			bf:      Bedfile{InputFormat: VcfIF, Padding: 10, PaddingType: SafePT, SortType: FidxST},
+		},
+		{
+			testing:    "padding without fasta index with bed input",
+			bf:         Bedfile{InputFormat: BedIF, Padding: 10, PaddingType: SafePT, SortType: LexST},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			tc.bf.Threads = 1 // default of --threads
+			err := tc.bf.VerifyAndHandle()
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestVerifyVcfChrLengths(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		bf         Bedfile
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "fidx sorting with contig lines",
+			bf: Bedfile{
+				InputFormat: VcfIF, SortType: FidxST,
+				chrOrderMap: map[string]int{"1": 1}, chrLengthMap: map[string]int{"1": 100},
+			},
+		},
+		{
+			testing:    "fidx sorting without contig lines",
+			bf:         Bedfile{InputFormat: VcfIF, SortType: FidxST},
+			shouldFail: true,
+		},
+		{
+			testing:    "safe padding without contig lines",
+			bf:         Bedfile{InputFormat: VcfIF, SortType: LexST, Padding: 10, PaddingType: SafePT},
+			shouldFail: true,
+		},
+		{
+			testing: "force padding without contig lines",
+			bf:      Bedfile{InputFormat: VcfIF, SortType: LexST, Padding: 10, PaddingType: ForcePT},
+		},
+		{
+			testing: "fidx sorting without contig lines, but with fasta index",
+			bf:      Bedfile{InputFormat: VcfIF, SortType: FidxST, FastaIdx: "test.fasta.fai"},
+		},
+		{
+			testing: "bed input is verified before reading",
+			bf:      Bedfile{InputFormat: BedIF, SortType: FidxST},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bf.VerifyVcfChrLengths()
+			if !tc.shouldFail && err != nil || tc.shouldFail && err == nil {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
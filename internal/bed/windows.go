@@ -0,0 +1,119 @@
+package bed
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Policies for the last window if it is shorter than the window size
+var KeepLW = "keep"     // keep the shorter window
+var DropLW = "drop"     // drop the shorter window
+var ExtendLW = "extend" // extend the window to the full size, within the chromosome borders if --fasta-idx is set
+
+type Windows struct {
+	Size       int    `env:"WINDOW_SIZE" group:"windows" required:"" help:"Width of the windows in bp"`
+	Step       int    `env:"WINDOW_STEP" group:"windows" help:"Distance in bp between the starts of two windows. If unset the step is equal to --size, so that the windows do not overlap"`
+	LastWindow string `env:"LAST_WINDOW" group:"windows" enum:"${keepLW},${dropLW},${extendLW}" default:"${keepLW}" help:"What to do with the last window of a region if it is shorter than --size. ${keepLW} = keep the shorter window, ${dropLW} = drop the shorter window, ${extendLW} = extend the window to the full size (within the chromosome borders if --fasta-idx is set)"`
+	WindowIdx  bool   `env:"WINDOW_IDX" group:"windows" help:"Add a column with the index of the window within the region or chromosome it was made from (1-based)"`
+}
+
+// Verifies and handles Windows input
+func (w *Windows) VerifyAndHandle() error {
+	if w.Size <= 0 {
+		return fmt.Errorf("--size must be larger than 0: %d", w.Size)
+	}
+	if w.Step < 0 {
+		return fmt.Errorf("--step can not be negative: %d", w.Step)
+	}
+	if w.Step == 0 {
+		w.Step = w.Size
+	}
+	return nil
+}
+
+// Split the lines into windows
+//
+// If there are no lines, windows are made for each chromosome
+// in the fasta index file instead. The optional columns of the
+// lines are kept for each of their windows.
+func (bf *Bedfile) MakeWindows(w Windows) error {
+	if !stringInSlice([]string{KeepLW, DropLW, ExtendLW}, w.LastWindow) {
+		return fmt.Errorf("unknown last window policy %s", w.LastWindow)
+	}
+	if w.Size <= 0 || w.Step <= 0 {
+		return fmt.Errorf("window size and step must be larger than 0: %d, %d", w.Size, w.Step)
+	}
+	regions := bf.Lines
+	if len(bf.Inputs) == 0 {
+		if bf.chrLengthMap == nil {
+			return fmt.Errorf("can't make windows without input bed files or a fasta index file")
+		}
+		regions = bf.chromosomeRegions()
+	}
+	var windows []Line
+	for _, l := range regions {
+		windows = append(windows, bf.splitIntoWindows(l, w)...)
+	}
+	bf.Lines = windows
+	return nil
+}
+
+// Regions covering the whole chromosomes in the fasta index file
+func (bf *Bedfile) chromosomeRegions() []Line {
+	var chrs []string
+	for chr := range bf.chrLengthMap {
+		chrs = append(chrs, chr)
+	}
+	slices.Sort(chrs)
+	regions := make([]Line, len(chrs))
+	for i, chr := range chrs {
+		regions[i] = newRegion(chr, bf.FirstBase, bf.chrLengthMap[chr])
+	}
+	return regions
+}
+
+// Split a single line into windows
+//
+// Windows are made until a window reaches the stop of the line, so
+// that no window is fully covered by the previous one when step is
+// smaller than size
+func (bf *Bedfile) splitIntoWindows(l Line, w Windows) []Line {
+	var windows []Line
+	for start := l.Start; start <= l.Stop-1+bf.FirstBase; start += w.Step {
+		// The stop of a full window, taking closed regions into account
+		stop := start + w.Size - bf.FirstBase
+		last := stop >= l.Stop
+		if stop > l.Stop {
+			switch w.LastWindow {
+			case KeepLW:
+				stop = l.Stop
+			case DropLW:
+				return windows
+			case ExtendLW:
+				if chrLength, ok := bf.chrLengthMap[l.Chr]; ok && stop > chrLength {
+					stop = chrLength
+				}
+			}
+		}
+		windows = append(windows, bf.newWindow(l, start, stop, len(windows)+1, w.WindowIdx))
+		if last {
+			break
+		}
+	}
+	return windows
+}
+
+// Create window from a line, with the window index
+// as an additional column if windowIdx is set
+func (bf *Bedfile) newWindow(l Line, start, stop, idx int, windowIdx bool) Line {
+	window := Line{
+		Chr: l.Chr, Start: start, Stop: stop,
+		Strand: l.Strand, Feat: l.Feat,
+		Opt: l.Opt,
+	}
+	if windowIdx {
+		window.setOptCols(append(l.optCols(), strconv.Itoa(idx)))
+	}
+	return window
+}
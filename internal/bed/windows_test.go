@@ -0,0 +1,254 @@
+package bed
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+var testWindowLines = []Line{
+	{
+		Chr: "1", Start: 10, Stop: 35,
+		Strand: "+",
+		Opt:    []byte("+"),
+	},
+	{
+		Chr: "2", Start: 190, Stop: 195,
+		Strand: "-",
+		Opt:    []byte("-"),
+	},
+}
+
+func TestMakeWindows(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing       string
+		bed           Bedfile
+		windows       Windows
+		expectedLines []Line
+		shouldFail    bool
+	}
+	testCases := []testCase{
+		{
+			testing: "keep last window",
+			bed: Bedfile{
+				Inputs: []string{"test.bed"},
+				Lines:  deepCopyLines(testWindowLines),
+			},
+			windows: Windows{Size: 10, Step: 10, LastWindow: KeepLW},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 10, Stop: 20,
+					Strand: "+",
+					Opt:    []byte("+"),
+				},
+				{
+					Chr: "1", Start: 20, Stop: 30,
+					Strand: "+",
+					Opt:    []byte("+"),
+				},
+				{
+					Chr: "1", Start: 30, Stop: 35,
+					Strand: "+",
+					Opt:    []byte("+"),
+				},
+				{
+					Chr: "2", Start: 190, Stop: 195,
+					Strand: "-",
+					Opt:    []byte("-"),
+				},
+			},
+		},
+		{
+			testing: "drop last window",
+			bed: Bedfile{
+				Inputs: []string{"test.bed"},
+				Lines:  deepCopyLines(testWindowLines),
+			},
+			windows: Windows{Size: 10, Step: 10, LastWindow: DropLW},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 10, Stop: 20,
+					Strand: "+",
+					Opt:    []byte("+"),
+				},
+				{
+					Chr: "1", Start: 20, Stop: 30,
+					Strand: "+",
+					Opt:    []byte("+"),
+				},
+			},
+		},
+		{
+			testing: "extend last window within chromosome borders",
+			bed: Bedfile{
+				Inputs:       []string{"test.bed"},
+				Lines:        deepCopyLines(testWindowLines),
+				chrLengthMap: testChrLengthMap,
+			},
+			windows: Windows{Size: 20, Step: 20, LastWindow: ExtendLW},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 10, Stop: 30,
+					Strand: "+",
+					Opt:    []byte("+"),
+				},
+				{
+					Chr: "1", Start: 30, Stop: 50,
+					Strand: "+",
+					Opt:    []byte("+"),
+				},
+				{
+					Chr: "2", Start: 190, Stop: 200,
+					Strand: "-",
+					Opt:    []byte("-"),
+				},
+			},
+		},
+		{
+			testing: "overlapping windows with window index",
+			bed: Bedfile{
+				Inputs: []string{"test.bed"},
+				Lines:  deepCopyLines(testWindowLines[:1]),
+			},
+			windows: Windows{Size: 20, Step: 10, LastWindow: KeepLW, WindowIdx: true},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 10, Stop: 30,
+					Strand: "+",
+					Opt:    []byte("+\t1"),
+				},
+				{
+					Chr: "1", Start: 20, Stop: 35,
+					Strand: "+",
+					Opt:    []byte("+\t2"),
+				},
+			},
+		},
+		{
+			testing: "first base 1",
+			bed: Bedfile{
+				Inputs:    []string{"test.bed"},
+				FirstBase: 1,
+				Lines: []Line{
+					{
+						Chr: "1", Start: 1, Stop: 25,
+					},
+				},
+			},
+			windows: Windows{Size: 10, Step: 10, LastWindow: KeepLW},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 1, Stop: 10,
+				},
+				{
+					Chr: "1", Start: 11, Stop: 20,
+				},
+				{
+					Chr: "1", Start: 21, Stop: 25,
+				},
+			},
+		},
+		{
+			testing: "chromosomes in fasta index",
+			bed: Bedfile{
+				chrLengthMap: map[string]int{"2": 200, "1": 150},
+			},
+			windows: Windows{Size: 100, Step: 100, LastWindow: KeepLW, WindowIdx: true},
+			expectedLines: []Line{
+				{
+					Chr: "1", Start: 0, Stop: 100,
+					Opt: []byte("1"),
+				},
+				{
+					Chr: "1", Start: 100, Stop: 150,
+					Opt: []byte("2"),
+				},
+				{
+					Chr: "2", Start: 0, Stop: 100,
+					Opt: []byte("1"),
+				},
+				{
+					Chr: "2", Start: 100, Stop: 200,
+					Opt: []byte("2"),
+				},
+			},
+		},
+		{
+			testing:    "neither inputs nor fasta index",
+			windows:    Windows{Size: 100, Step: 100, LastWindow: KeepLW},
+			shouldFail: true,
+		},
+		{
+			testing: "unknown last window policy",
+			bed: Bedfile{
+				Inputs: []string{"test.bed"},
+				Lines:  deepCopyLines(testWindowLines),
+			},
+			windows:    Windows{Size: 10, Step: 10, LastWindow: "shrink"},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.bed.MakeWindows(tc.windows)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedLines, tc.bed.Lines); diff != nil {
+					t.Error("expected VS received lines", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWindowsVerifyAndHandle(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing         string
+		windows         Windows
+		expectedWindows Windows
+		shouldFail      bool
+	}
+	testCases := []testCase{
+		{
+			testing:         "step defaults to size",
+			windows:         Windows{Size: 100},
+			expectedWindows: Windows{Size: 100, Step: 100},
+		},
+		{
+			testing:         "step set",
+			windows:         Windows{Size: 100, Step: 50},
+			expectedWindows: Windows{Size: 100, Step: 50},
+		},
+		{
+			testing:    "size 0",
+			windows:    Windows{Size: 0},
+			shouldFail: true,
+		},
+		{
+			testing:    "negative step",
+			windows:    Windows{Size: 100, Step: -1},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			err := tc.windows.VerifyAndHandle()
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+			if !tc.shouldFail {
+				if diff := deep.Equal(tc.expectedWindows, tc.windows); diff != nil {
+					t.Error("expected VS received windows", diff)
+				}
+			}
+		})
+	}
+}
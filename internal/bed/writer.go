@@ -1,6 +1,7 @@
 package bed
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
@@ -9,9 +10,15 @@ import (
 
 // Writing bed file or standard output
 func (bf *Bedfile) Write() error {
+	return bf.openOutput(bf.write)
+}
+
+// Open the output file, or standard output if output is not set,
+// and pass it on to write
+func (bf *Bedfile) openOutput(write func(writer io.Writer) error) error {
 	// If output is not set write to Stdout
 	if bf.Output == "" {
-		return bf.write(os.Stdout)
+		return write(os.Stdout)
 	}
 
 	// If output is set write to file
@@ -20,11 +27,33 @@ func (bf *Bedfile) Write() error {
 		return fmt.Errorf("cannot create output file: %v", err)
 	}
 	defer file.Close()
-	return bf.write(file)
+	return write(file)
+}
+
+// Writing bed file to a writer instead of the output path
+func (bf *Bedfile) WriteBedTo(writer io.Writer) error {
+	return bf.write(writer)
 }
 
 // Write bedfile content as string to writer destination
 func (bf *Bedfile) write(writer io.Writer) error {
+	if bf.Bgzip {
+		lw := bf.newLineWriter(writer)
+		for _, h := range bf.Header {
+			if err := lw.writeHeader(h); err != nil {
+				return err
+			}
+		}
+		for _, l := range bf.Lines {
+			if err := lw.writeLine(l); err != nil {
+				return err
+			}
+		}
+		if err := lw.writeComments(bf.comments); err != nil {
+			return err
+		}
+		return lw.close()
+	}
 	reader := strings.NewReader(bf.toString())
 	_, err := io.Copy(writer, reader)
 	return err
@@ -38,9 +67,118 @@ func (bf *Bedfile) toString() string {
 	if len(bf.Header) > 0 {
 		bedAsString = fmt.Sprintf("%s\n", strings.Join(bf.Header, "\n"))
 	}
-	// Add lines
+	// Add lines with the comments in front of them
 	for _, l := range bf.Lines {
-		bedAsString = fmt.Sprintf("%s%s\n", bedAsString, strings.Join(l.Full, "\t"))
+		for _, c := range l.Comments {
+			bedAsString = fmt.Sprintf("%s%s\n", bedAsString, c)
+		}
+		bedAsString = fmt.Sprintf("%s%s\n", bedAsString, l.text())
+	}
+	// Add comments after the last line
+	for _, c := range bf.comments {
+		bedAsString = fmt.Sprintf("%s%s\n", bedAsString, c)
 	}
 	return bedAsString
 }
+
+// Writes headers and lines one by one, BGZF compressed
+// and indexed if --bgzip and --tabix are set
+type lineWriter struct {
+	bf      *Bedfile
+	buffer  *bufio.Writer
+	bgzf    *bgzfWriter
+	indexer *tabixIndexer
+}
+
+// Create new line writer
+func (bf *Bedfile) newLineWriter(writer io.Writer) *lineWriter {
+	lw := &lineWriter{bf: bf}
+	if bf.Bgzip {
+		lw.bgzf = newBgzfWriter(writer)
+		writer = lw.bgzf
+	}
+	if bf.Tabix {
+		// The header lines are counted as they are written, as the
+		// header of presorted files is only known after reading
+		lw.indexer = newTabixIndexer(0)
+	}
+	lw.buffer = bufio.NewWriter(writer)
+	return lw
+}
+
+// Write header line, it is skipped by the index
+func (lw *lineWriter) writeHeader(header string) error {
+	if lw.indexer != nil {
+		lw.indexer.skip++
+	}
+	_, err := fmt.Fprintf(lw.buffer, "%s\n", header)
+	return err
+}
+
+// Write comments, they are skipped by the index as they start with #
+func (lw *lineWriter) writeComments(comments []string) error {
+	for _, c := range comments {
+		if _, err := fmt.Fprintf(lw.buffer, "%s\n", c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write line, with the comments in front of it, and add it to the index
+func (lw *lineWriter) writeLine(l Line) error {
+	if lw.indexer == nil {
+		return lw.writeText(l.text(), l.Comments)
+	}
+	if err := lw.writeComments(l.Comments); err != nil {
+		return err
+	}
+	// The buffer has to be empty to get the current offset
+	if err := lw.buffer.Flush(); err != nil {
+		return err
+	}
+	beg := lw.bgzf.offset()
+	if _, err := fmt.Fprintf(lw.bgzf, "%s\n", l.text()); err != nil {
+		return err
+	}
+	return lw.indexer.add(l.Chr, l.Start, l.Stop, beg, lw.bgzf.offset())
+}
+
+// Write line of text that is not indexed, with the comments in front of it
+func (lw *lineWriter) writeText(text string, comments []string) error {
+	if err := lw.writeComments(comments); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(lw.buffer, "%s\n", text)
+	return err
+}
+
+// Flush the remaining content and write the index file
+func (lw *lineWriter) close() error {
+	if err := lw.buffer.Flush(); err != nil {
+		return err
+	}
+	if lw.bgzf != nil {
+		if err := lw.bgzf.Close(); err != nil {
+			return err
+		}
+	}
+	if lw.indexer != nil {
+		return lw.bf.writeIndex(lw.indexer)
+	}
+	return nil
+}
+
+// Write the index next to the output file
+func (bf *Bedfile) writeIndex(indexer *tabixIndexer) error {
+	indexPath := bf.Output + indexer.extension()
+	file, err := os.Create(indexPath)
+	if err != nil {
+		return fmt.Errorf("cannot create index file: %v", err)
+	}
+	defer file.Close()
+	if err := indexer.write(file); err != nil {
+		return fmt.Errorf("cannot write index file %s: %v", indexPath, err)
+	}
+	return nil
+}
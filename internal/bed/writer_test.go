@@ -27,19 +27,15 @@ func TestWrite(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
-						Full: []string{"2", "20", "200"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 				},
 			},
@@ -98,19 +94,15 @@ func TestToString(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
-						Full: []string{"2", "20", "200"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 				},
 			},
@@ -131,19 +123,15 @@ func TestToString(t *testing.T) {
 				Lines: []Line{
 					{
 						Chr: "1", Start: 10, Stop: 100,
-						Full: []string{"1", "10", "100"},
 					},
 					{
 						Chr: "2", Start: 20, Stop: 200,
-						Full: []string{"2", "20", "200"},
 					},
 					{
 						Chr: "3", Start: 30, Stop: 300,
-						Full: []string{"3", "30", "300"},
 					},
 					{
 						Chr: "4", Start: 40, Stop: 400,
-						Full: []string{"4", "40", "400"},
 					},
 				},
 			},
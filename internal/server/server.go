@@ -0,0 +1,428 @@
+// Package server exposes the sorting, merging and padding of bed files
+// as an HTTP API, using the bedfusion package to handle each request.
+// The server only works on the uploaded files, it never reads or writes
+// files on the host and never calls other services.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hbesfb/bedfusion"
+)
+
+// Names of the multipart form fields of a request
+const (
+	optionsField  = "options"   // options as JSON
+	bedField      = "bed"       // bed file, can be repeated
+	fastaIdxField = "fasta-idx" // genome file with the chromosome sizes
+	chrAliasField = "chr-alias" // chromosome alias file
+)
+
+// Header with the warnings of a request, one value per warning
+const warningHeader = "Bedfusion-Warning"
+
+// Limits of the server
+type Config struct {
+	MaxBodySize int64         // largest accepted request body in bytes
+	Timeout     time.Duration // longest time a request can take, 0 = no limit
+	MaxRequests int           // number of requests handled at the same time
+	MaxThreads  int           // largest number of threads a request can use
+}
+
+// Server handling the requests
+type Server struct {
+	config Config
+	mux    *http.ServeMux
+	// Holds one value for each request being handled
+	requests chan struct{}
+	// Sorts, merges and pads the bed files and writes the output
+	fuseAndWrite func(bf *bedfusion.Bedfile, output io.Writer) error
+}
+
+// Options of a request, named after the fields of the bed files of the
+// command line tool, so that {"StrandCol": 4, "Padding": 10} gives the
+// same result as --strand-col=4 --padding=10. Options reading or
+// writing files on the host are not available, the fasta index
+// and chromosome alias files are uploaded with the bed files instead
+type options struct {
+	StrandCol     int
+	FeatCol       int
+	ChrAliasSet   string
+	ChrNaming     string
+	InputFormat   string
+	Bed12         bool
+	Schema        int
+	SchemaFill    string
+	ColMap        []string
+	FeatureType   []string
+	AttrFilter    []string
+	Attr          []string
+	FeatAttr      string
+	InfoField     []string
+	SortType      string
+	ChrOrder      []string
+	Deduplicate   bool
+	Threads       int
+	NoMerge       bool
+	Overlap       int
+	ColOp         []string
+	Padding       int
+	PaddingType   string
+	FirstBase     int
+	PadUpstream   int
+	PadDownstream int
+	PadLeft       int
+	PadRight      int
+	Bgzip         bool
+	HeaderPolicy  string
+	TrackLine     string
+	CommentPolicy string
+}
+
+// Error returned to the client with its status code
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+// Create new server
+func New(config Config) (*Server, error) {
+	if config.MaxBodySize <= 0 {
+		return nil, fmt.Errorf("--max-body-size must be larger than 0: %d", config.MaxBodySize)
+	}
+	if config.Timeout < 0 {
+		return nil, fmt.Errorf("--timeout can not be negative: %s", config.Timeout)
+	}
+	if config.MaxRequests < 1 {
+		return nil, fmt.Errorf("--max-requests must be at least 1: %d", config.MaxRequests)
+	}
+	if config.MaxThreads < 1 {
+		return nil, fmt.Errorf("--max-threads must be at least 1: %d", config.MaxThreads)
+	}
+	s := &Server{
+		config:       config,
+		mux:          http.NewServeMux(),
+		requests:     make(chan struct{}, config.MaxRequests),
+		fuseAndWrite: fuseAndWrite,
+	}
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("POST /fuse", s.handleFuse)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Report that the server is running
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Sort, merge and pad the uploaded bed files in the same way
+// as the default command of the command line tool
+//
+// The bed files are either uploaded as a multipart form, together with
+// the options and the optional fasta index and chromosome alias files,
+// or sent as the request body with the options in the options query
+// parameter.
+func (s *Server) handleFuse(w http.ResponseWriter, r *http.Request) {
+	select {
+	case s.requests <- struct{}{}:
+		// The slot is given back by fuse
+	default:
+		writeError(w, requestError{http.StatusServiceUnavailable,
+			fmt.Errorf("the server is already handling %d requests", s.config.MaxRequests)})
+		return
+	}
+
+	ctx := r.Context()
+	if s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancel()
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxBodySize)
+
+	var warnings bytes.Buffer
+	output, err := s.fuse(ctx, r, &warnings)
+	if err != nil {
+		// Nobody is listening if the client cancelled the request
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+		writeError(w, err)
+		return
+	}
+	for _, warning := range strings.Split(strings.TrimSpace(warnings.String()), "\n") {
+		if warning != "" {
+			w.Header().Add(warningHeader, warning)
+		}
+	}
+	w.Header().Set("Content-Type", contentType(output.Bytes()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(output.Bytes())
+}
+
+// Handle the request, returning the output and writing
+// the warnings of the bedfusion package to warnings
+//
+// The request slot taken by handleFuse is given back when the request
+// is handled, or when the abandoned work of a timed out request is done.
+func (s *Server) fuse(ctx context.Context, r *http.Request, warnings io.Writer) (*bytes.Buffer, error) {
+	abandoned := false
+	defer func() {
+		if !abandoned {
+			<-s.requests
+		}
+	}()
+	var (
+		rawOptions string
+		beds       []*multipart.FileHeader
+		opts       bedfusion.Options
+	)
+	isMultipart := strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+	if isMultipart {
+		// The body is limited by MaxBodySize, so the whole form is kept in memory
+		if err := r.ParseMultipartForm(s.config.MaxBodySize); err != nil {
+			return nil, s.bodyError(err)
+		}
+		rawOptions = r.FormValue(optionsField)
+		beds = r.MultipartForm.File[bedField]
+		if len(beds) == 0 {
+			return nil, requestError{http.StatusBadRequest, fmt.Errorf("at least one %s file must be uploaded", bedField)}
+		}
+		var err error
+		if opts.FastaIdx, err = formInput(ctx, r.MultipartForm, fastaIdxField); err != nil {
+			return nil, err
+		}
+		if opts.ChrAlias, err = formInput(ctx, r.MultipartForm, chrAliasField); err != nil {
+			return nil, err
+		}
+	} else {
+		rawOptions = r.URL.Query().Get(optionsField)
+	}
+	if err := parseOptions(rawOptions, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Threads > s.config.MaxThreads {
+		return nil, requestError{http.StatusBadRequest,
+			fmt.Errorf("the Threads option can be at most %d: %d", s.config.MaxThreads, opts.Threads)}
+	}
+	opts.Warnings = warnings
+
+	bf, err := bedfusion.New(opts)
+	if err != nil {
+		return nil, requestError{http.StatusBadRequest, err}
+	}
+	if isMultipart {
+		for _, bed := range beds {
+			input, err := openFormFile(ctx, bed)
+			if err != nil {
+				return nil, err
+			}
+			if err := bf.Read(input.Name, input.Reader); err != nil {
+				return nil, s.readError(ctx, nil, err)
+			}
+		}
+	} else if err := bf.Read("request body", contextReader{ctx, r.Body}); err != nil {
+		return nil, s.readError(ctx, r.Body, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
+	}
+	// Sorting, merging and padding can not be interrupted, so they are
+	// done in the background and the request is answered as soon as the
+	// context is done. The result of an abandoned request is dropped, but
+	// it keeps its slot until it is done so that --max-requests still
+	// limits the work of the server
+	done := make(chan error, 1)
+	var output bytes.Buffer
+	go func() {
+		done <- s.fuseAndWrite(bf, &output)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, err
+		}
+		return &output, nil
+	case <-ctx.Done():
+		abandoned = true
+		go func() {
+			<-done
+			<-s.requests
+		}()
+		return nil, contextError(ctx.Err())
+	}
+}
+
+// Sort, merge and pad the bed files and write the output
+func fuseAndWrite(bf *bedfusion.Bedfile, output io.Writer) error {
+	if err := bf.Fuse(); err != nil {
+		return requestError{http.StatusBadRequest, err}
+	}
+	if err := bf.Write(output); err != nil {
+		return requestError{http.StatusInternalServerError, err}
+	}
+	return nil
+}
+
+// Parse the JSON options into opts, options
+// that are not known are not accepted
+func parseOptions(rawOptions string, opts *bedfusion.Options) error {
+	var o options
+	if rawOptions != "" {
+		decoder := json.NewDecoder(strings.NewReader(rawOptions))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&o); err != nil {
+			return requestError{http.StatusBadRequest, fmt.Errorf("invalid options: %w", err)}
+		}
+	}
+	opts.StrandCol = o.StrandCol
+	opts.FeatCol = o.FeatCol
+	opts.ChrAliasSet = o.ChrAliasSet
+	opts.ChrNaming = o.ChrNaming
+	opts.InputFormat = o.InputFormat
+	opts.Bed12 = o.Bed12
+	opts.Schema = o.Schema
+	opts.SchemaFill = o.SchemaFill
+	opts.ColMaps = o.ColMap
+	opts.FeatureTypes = o.FeatureType
+	opts.AttrFilters = o.AttrFilter
+	opts.Attrs = o.Attr
+	opts.FeatAttr = o.FeatAttr
+	opts.InfoFields = o.InfoField
+	opts.SortType = o.SortType
+	opts.ChrOrder = o.ChrOrder
+	opts.Deduplicate = o.Deduplicate
+	opts.Threads = o.Threads
+	opts.NoMerge = o.NoMerge
+	opts.Overlap = o.Overlap
+	opts.ColOps = o.ColOp
+	opts.Padding = o.Padding
+	opts.PaddingType = o.PaddingType
+	opts.FirstBase = o.FirstBase
+	opts.PadUpstream = o.PadUpstream
+	opts.PadDownstream = o.PadDownstream
+	opts.PadLeft = o.PadLeft
+	opts.PadRight = o.PadRight
+	opts.Bgzip = o.Bgzip
+	opts.HeaderPolicy = o.HeaderPolicy
+	opts.TrackLine = o.TrackLine
+	opts.CommentPolicy = o.CommentPolicy
+	return nil
+}
+
+// Returns the uploaded file of the form field, or nil if it is not uploaded
+func formInput(ctx context.Context, form *multipart.Form, field string) (*bedfusion.Input, error) {
+	files := form.File[field]
+	switch len(files) {
+	case 0:
+		return nil, nil
+	case 1:
+		return openFormFile(ctx, files[0])
+	default:
+		return nil, requestError{http.StatusBadRequest, fmt.Errorf("only one %s file can be uploaded", field)}
+	}
+}
+
+// Open uploaded file, named after the uploaded file name
+func openFormFile(ctx context.Context, header *multipart.FileHeader) (*bedfusion.Input, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, requestError{http.StatusBadRequest, err}
+	}
+	// The form is kept in memory, so the file does not need to be closed
+	return &bedfusion.Input{Name: header.Filename, Reader: contextReader{ctx, file}}, nil
+}
+
+// Reader that stops reading when the context is done,
+// so that cancelled requests stop as soon as possible
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// Error of reading the bed files, which are bad requests unless
+// the body was too large or the context is done
+//
+// The last lines of a too large body are parsed before the error of
+// the body is seen, so the body is read once more to find out if it
+// was too large. Bodies limited by http.MaxBytesReader keep returning
+// the error once the limit is reached.
+func (s *Server) readError(ctx context.Context, body io.Reader, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return contextError(ctxErr)
+	}
+	if body != nil {
+		if _, bodyErr := body.Read(make([]byte, 1)); bodyErr != nil && bodyErr != io.EOF {
+			return s.bodyError(bodyErr)
+		}
+	}
+	return s.bodyError(err)
+}
+
+// Error of reading the request body. The bedfusion package does not wrap
+// the errors of the readers, so the message of too large bodies is matched
+func (s *Server) bodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || strings.Contains(err.Error(), "request body too large") {
+		return requestError{http.StatusRequestEntityTooLarge,
+			fmt.Errorf("the request body is larger than %d bytes", s.config.MaxBodySize)}
+	}
+	return requestError{http.StatusBadRequest, err}
+}
+
+// Error of a request that was cancelled or timed out
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return requestError{http.StatusServiceUnavailable, fmt.Errorf("the request timed out")}
+	}
+	return requestError{http.StatusServiceUnavailable, err}
+}
+
+// Content type of the output, which is BGZF compressed if Bgzip is set
+func contentType(output []byte) string {
+	if bytes.HasPrefix(output, []byte{0x1f, 0x8b}) {
+		return "application/gzip"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Write the error as JSON
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var reqErr requestError
+	if errors.As(err, &reqErr) {
+		status = reqErr.status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// Write the value as JSON with the status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
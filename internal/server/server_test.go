@@ -0,0 +1,413 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/hbesfb/bedfusion"
+)
+
+const testBed = "1\t5\t8\t1\tA\n" +
+	"1\t1\t4\t1\tA\n" +
+	"1\t5\t8\t-1\tA\n" +
+	"2\t5\t8\t1\tA\n" +
+	"1\t6\t8\t1\tB\n"
+
+var testConfig = Config{MaxBodySize: 1 << 20, Timeout: time.Minute, MaxRequests: 2, MaxThreads: 2}
+
+// File uploaded in a multipart form
+type formFile struct {
+	field   string
+	name    string
+	content string
+}
+
+// Create multipart request to /fuse
+func multipartRequest(t *testing.T, options string, files []formFile) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if options != "" {
+		if err := mw.WriteField(optionsField, options); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range files {
+		fw, err := mw.CreateFormFile(f.field, f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(f.content))
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/fuse", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+// Create request to /fuse with the bed file as the body
+func bodyRequest(options string, bed string) *http.Request {
+	target := "/fuse"
+	if options != "" {
+		target += "?" + url.Values{optionsField: {options}}.Encode()
+	}
+	return httptest.NewRequest(http.MethodPost, target, strings.NewReader(bed))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing    string
+		config     Config
+		shouldFail bool
+	}
+	testCases := []testCase{
+		{
+			testing: "correct config",
+			config:  testConfig,
+		},
+		{
+			testing: "no timeout",
+			config:  Config{MaxBodySize: 1, MaxRequests: 1, MaxThreads: 1},
+		},
+		{
+			testing:    "no max body size",
+			config:     Config{MaxRequests: 1, MaxThreads: 1},
+			shouldFail: true,
+		},
+		{
+			testing:    "negative timeout",
+			config:     Config{MaxBodySize: 1, Timeout: -time.Second, MaxRequests: 1, MaxThreads: 1},
+			shouldFail: true,
+		},
+		{
+			testing:    "no max requests",
+			config:     Config{MaxBodySize: 1, MaxThreads: 1},
+			shouldFail: true,
+		},
+		{
+			testing:    "no max threads",
+			config:     Config{MaxBodySize: 1, MaxRequests: 1},
+			shouldFail: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tc.config)
+			if (!tc.shouldFail && err != nil) || (tc.shouldFail && err == nil) {
+				t.Fatalf("shouldFail is %t, but err is %q", tc.shouldFail, err)
+			}
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+	s, err := New(testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if diff := deep.Equal(http.StatusOK, w.Code); diff != nil {
+		t.Error("expected VS received status", diff)
+	}
+	if diff := deep.Equal("{\"status\":\"ok\"}\n", w.Body.String()); diff != nil {
+		t.Error("expected VS received body", diff)
+	}
+}
+
+func TestFuse(t *testing.T) {
+	t.Parallel()
+	type testCase struct {
+		testing          string
+		request          func(t *testing.T) *http.Request
+		expectedStatus   int
+		expectedBody     string
+		expectedWarnings []string
+	}
+	testCases := []testCase{
+		{
+			testing: "bed file as body with default options",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest("", testBed)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "1\t1\t8\t1,-1\tA,B\n" +
+				"2\t5\t8\t1\tA\n",
+		},
+		{
+			testing: "bed file as body with options",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest(`{"StrandCol": 4, "FeatCol": 5, "SortType": "nat"}`, testBed)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "1\t1\t8\t1\tA\n" +
+				"1\t5\t8\t-1\tA\n" +
+				"1\t6\t8\t1\tB\n" +
+				"2\t5\t8\t1\tA\n",
+		},
+		{
+			testing: "several uploaded bed files with fasta index",
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t, `{"Padding": 10, "PaddingType": "lax", "NoMerge": true}`, []formFile{
+					{field: bedField, name: "a.bed", content: "1\t20\t30\n"},
+					{field: bedField, name: "b.bed", content: "2\t5\t8\n"},
+					{field: fastaIdxField, name: "genome.dict", content: "@SQ\tSN:1\tLN:35\n"},
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "1\t10\t35\n" +
+				"2\t5\t8\n",
+			expectedWarnings: []string{
+				"warning: chromosomes [2] not in fasta index file genome.dict, no padding was added to regions on these chromosomes",
+			},
+		},
+		{
+			testing: "column maps match the uploaded file names",
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t, `{"Schema": 4, "ColMap": ["b.bed=1,2,3,5"], "NoMerge": true}`, []formFile{
+					{field: bedField, name: "a.bed", content: "1\t20\t30\tA\n"},
+					{field: bedField, name: "b.bed", content: "1\t5\t8\t+\tB\n"},
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: "1\t5\t8\tB\n" +
+				"1\t20\t30\tA\n",
+		},
+		{
+			testing: "options reading files on the host are unknown",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest(`{"FastaIdx": "/etc/passwd"}`, testBed)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid options: json: unknown field \"FastaIdx\""}` + "\n",
+		},
+		{
+			testing: "more threads than allowed by the server",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest(`{"Threads": 3}`, testBed)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"the Threads option can be at most 2: 3"}` + "\n",
+		},
+		{
+			testing: "invalid JSON",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest(`{"Padding": "ten"}`, testBed)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testing: "invalid combination of options",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest(`{"Padding": 10}`, testBed)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"--padding-type=safe must be used together with --fasta-idx"}` + "\n",
+		},
+		{
+			testing: "invalid bed file",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest("", "1\t5\n")
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testing: "multipart form without bed files",
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t, `{"NoMerge": true}`, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"at least one bed file must be uploaded"}` + "\n",
+		},
+		{
+			testing: "more than one fasta index file",
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t, "", []formFile{
+					{field: bedField, name: "a.bed", content: "1\t20\t30\n"},
+					{field: fastaIdxField, name: "a.fai", content: "1\t100\n"},
+					{field: fastaIdxField, name: "b.fai", content: "1\t100\n"},
+				})
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testing: "too large body",
+			request: func(t *testing.T) *http.Request {
+				return bodyRequest("", strings.Repeat("1\t5\t8\n", 1<<18))
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"the request body is larger than 1048576 bytes"}` + "\n",
+		},
+		{
+			testing: "too large multipart form",
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t, "", []formFile{
+					{field: bedField, name: "a.bed", content: strings.Repeat("1\t5\t8\n", 1<<18)},
+				})
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			testing: "wrong method",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/fuse", nil)
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	s, err := New(testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testing, func(t *testing.T) {
+			// The requests are handled one by one, so
+			// that the server is never too busy
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, tc.request(t))
+			if diff := deep.Equal(tc.expectedStatus, w.Code); diff != nil {
+				t.Error("expected VS received status", diff, w.Body.String())
+			}
+			if tc.expectedBody != "" {
+				if diff := deep.Equal(tc.expectedBody, w.Body.String()); diff != nil {
+					t.Error("expected VS received body", diff)
+				}
+			}
+			if diff := deep.Equal(tc.expectedWarnings, w.Header().Values(warningHeader)); diff != nil {
+				t.Error("expected VS received warnings", diff)
+			}
+		})
+	}
+}
+
+func TestFuseBgzip(t *testing.T) {
+	t.Parallel()
+	s, err := New(testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, bodyRequest(`{"Bgzip": true}`, testBed))
+	if diff := deep.Equal(http.StatusOK, w.Code); diff != nil {
+		t.Fatal("expected VS received status", diff, w.Body.String())
+	}
+	if diff := deep.Equal("application/gzip", w.Header().Get("Content-Type")); diff != nil {
+		t.Error("expected VS received content type", diff)
+	}
+}
+
+func TestFuseLimits(t *testing.T) {
+	t.Parallel()
+	t.Run("too many requests", func(t *testing.T) {
+		t.Parallel()
+		s, err := New(Config{MaxBodySize: 1 << 20, MaxRequests: 1, MaxThreads: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Occupy the only request slot
+		s.requests <- struct{}{}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, bodyRequest("", testBed))
+		if diff := deep.Equal(http.StatusServiceUnavailable, w.Code); diff != nil {
+			t.Error("expected VS received status", diff)
+		}
+		// The health endpoint is not limited
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		if diff := deep.Equal(http.StatusOK, w.Code); diff != nil {
+			t.Error("expected VS received health status", diff)
+		}
+		<-s.requests
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, bodyRequest("", testBed))
+		if diff := deep.Equal(http.StatusOK, w.Code); diff != nil {
+			t.Error("expected VS received status after the slot is free", diff)
+		}
+	})
+	t.Run("timed out request", func(t *testing.T) {
+		t.Parallel()
+		s, err := New(Config{MaxBodySize: 1 << 20, Timeout: time.Nanosecond, MaxRequests: 1, MaxThreads: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, bodyRequest("", testBed))
+		if diff := deep.Equal(http.StatusServiceUnavailable, w.Code); diff != nil {
+			t.Error("expected VS received status", diff)
+		}
+		if diff := deep.Equal(`{"error":"the request timed out"}`+"\n", w.Body.String()); diff != nil {
+			t.Error("expected VS received body", diff)
+		}
+	})
+	t.Run("request timed out while fusing", func(t *testing.T) {
+		t.Parallel()
+		s, err := New(Config{MaxBodySize: 1 << 20, Timeout: 50 * time.Millisecond, MaxRequests: 1, MaxThreads: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Fusing does not finish before the deadline has passed
+		release := make(chan struct{})
+		finished := make(chan struct{})
+		s.fuseAndWrite = func(bf *bedfusion.Bedfile, output io.Writer) error {
+			defer close(finished)
+			<-release
+			return fuseAndWrite(bf, output)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, bodyRequest("", testBed))
+		if diff := deep.Equal(http.StatusServiceUnavailable, w.Code); diff != nil {
+			t.Error("expected VS received status", diff)
+		}
+		if diff := deep.Equal(`{"error":"the request timed out"}`+"\n", w.Body.String()); diff != nil {
+			t.Error("expected VS received body", diff)
+		}
+		// The abandoned work keeps the request slot until it is done
+		if diff := deep.Equal(1, len(s.requests)); diff != nil {
+			t.Error("expected VS received requests being handled while fusing", diff)
+		}
+		close(release)
+		<-finished
+		deadline := time.Now().Add(time.Second)
+		for len(s.requests) != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if diff := deep.Equal(0, len(s.requests)); diff != nil {
+			t.Error("expected VS received requests being handled after fusing", diff)
+		}
+	})
+	t.Run("cancelled request", func(t *testing.T) {
+		t.Parallel()
+		s, err := New(testConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, bodyRequest("", testBed).WithContext(ctx))
+		// Nothing is written to a client that has gone away
+		if diff := deep.Equal("", w.Body.String()); diff != nil {
+			t.Error("expected VS received body", diff)
+		}
+		// The request slot is freed
+		if diff := deep.Equal(0, len(s.requests)); diff != nil {
+			t.Error("expected VS received requests being handled", diff)
+		}
+	})
+}